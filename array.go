@@ -0,0 +1,100 @@
+package buildsql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ArrayDialect is implemented by dialects with native array columns
+type ArrayDialect interface {
+	// ArrayContains returns the predicate matching a scalar param
+	// against any element of the array column
+	ArrayContains(column, param string) string
+	// ArrayIn returns the predicate matching the column against any
+	// element of an array param, or none of them when negated
+	ArrayIn(column, param string, negate bool) string
+	// ArrayContainsAll returns the predicate matching an array column
+	// holding every param, or not holding all of them when negated
+	ArrayContainsAll(column string, params []string, negate bool) string
+}
+
+func (postgres) ArrayContains(column, param string) string {
+	return fmt.Sprintf("%s = ANY(%s)", param, column)
+}
+
+func (postgres) ArrayIn(column, param string, negate bool) string {
+	if negate {
+		return fmt.Sprintf("%s <> ALL(%s)", column, param)
+	}
+	return fmt.Sprintf("%s = ANY(%s)", column, param)
+}
+
+func (postgres) ArrayContainsAll(column string, params []string, negate bool) string {
+	contains := fmt.Sprintf("%s @> ARRAY[%s]", column, strings.Join(params, ", "))
+	if negate {
+		return "NOT (" + contains + ")"
+	}
+	return contains
+}
+
+// arrayContains renders anyeq through the dialect
+// without a dialect the postgres form is used, since only
+// postgres has array columns among the built in dialects
+func (b *QueryBuilder) arrayContains(column, param string) (string, error) {
+	if b.Dialect == nil {
+		return postgres{}.ArrayContains(column, param), nil
+	}
+	dialect, ok := b.Dialect.(ArrayDialect)
+	if !ok {
+		return "", fmt.Errorf("operator %s requires a dialect with array columns", AnyEqual)
+	}
+	return dialect.ArrayContains(column, param), nil
+}
+
+// arrayIn renders in and notin as a single array param through the dialect
+func (b *QueryBuilder) arrayIn(column, param string, negate bool) (string, error) {
+	if b.Dialect == nil {
+		return postgres{}.ArrayIn(column, param, negate), nil
+	}
+	dialect, ok := b.Dialect.(ArrayDialect)
+	if !ok {
+		return "", fmt.Errorf("ArrayInLists requires a dialect with array columns")
+	}
+	return dialect.ArrayIn(column, param, negate), nil
+}
+
+// arrayContainsAll renders contains and ncontains through the dialect
+func (b *QueryBuilder) arrayContainsAll(column string, params []string, negate bool) (string, error) {
+	if b.Dialect == nil {
+		return postgres{}.ArrayContainsAll(column, params, negate), nil
+	}
+	dialect, ok := b.Dialect.(ArrayDialect)
+	if !ok {
+		op := Contains
+		if negate {
+			op = NotContains
+		}
+		return "", fmt.Errorf("operator %s requires a dialect with array columns", op)
+	}
+	return dialect.ArrayContainsAll(column, params, negate), nil
+}
+
+// typedSlice converts the values to a slice of their shared type,
+// e.g. []int64, which drivers can bind as an array; mixed values
+// stay a []interface{}
+func typedSlice(values []interface{}) interface{} {
+	if len(values) == 0 || values[0] == nil {
+		return values
+	}
+
+	rt := reflect.TypeOf(values[0])
+	slice := reflect.MakeSlice(reflect.SliceOf(rt), 0, len(values))
+	for _, value := range values {
+		if reflect.TypeOf(value) != rt {
+			return values
+		}
+		slice = reflect.Append(slice, reflect.ValueOf(value))
+	}
+	return slice.Interface()
+}
@@ -0,0 +1,117 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+type Article struct {
+	ID   int64    `json:"id" db:"id"`
+	Tags []string `json:"tags" db:"tags"`
+}
+
+func TestQueryBuilderAnyEqual(t *testing.T) {
+	allowed := map[string]interface{}{
+		"a": Article{},
+	}
+
+	t.Run("should render ANY under postgres", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+
+		where, _, namedParamMap, err := builder.Build("filter=a-tags-anyeq-golang", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND :filter_a_tags_0 = ANY("a"."tags")`, where)
+		assert.Equal(t, "golang", namedParamMap["filter_a_tags_0"])
+	})
+
+	t.Run("should render ANY without a dialect", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=a-tags-anyeq-golang", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND :filter_a_tags_0 = ANY(a.tags)", where)
+	})
+
+	t.Run("should reject anyeq under mysql", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+
+		_, _, _, err := builder.Build("filter=a-tags-anyeq-golang", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderArrayInLists(t *testing.T) {
+	allowed := map[string]interface{}{
+		"a": Article{},
+	}
+
+	t.Run("should bind an in list as a single array param", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+		builder.ArrayInLists = true
+		builder.CoerceValues = true
+
+		where, _, namedParamMap, err := builder.Build("filter=a-id-in-1,2,3", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "a"."id" = ANY(:filter_a_id_0)`, where)
+		assert.Equal(t, 1, len(namedParamMap))
+		assert.Equal(t, []int64{1, 2, 3}, namedParamMap["filter_a_id_0"])
+	})
+
+	t.Run("should render notin as ALL", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+		builder.ArrayInLists = true
+
+		where, _, namedParamMap, err := builder.Build("filter=a-id-notin-1,2", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "a"."id" <> ALL(:filter_a_id_0)`, where)
+		assert.Equal(t, []string{"1", "2"}, namedParamMap["filter_a_id_0"])
+	})
+
+	t.Run("should reject array in lists under mysql", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+		builder.ArrayInLists = true
+
+		_, _, _, err := builder.Build("filter=a-id-in-1,2", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderContains(t *testing.T) {
+	allowed := map[string]interface{}{
+		"a": Article{},
+	}
+
+	t.Run("should render the negated containment under postgres", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+
+		where, _, namedParamMap, err := builder.Build("filter=a-tags-ncontains-golang,rust", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND NOT ("a"."tags" @> ARRAY[:filter_a_tags_0_0, :filter_a_tags_0_1])`, where)
+		assert.Equal(t, "golang", namedParamMap["filter_a_tags_0_0"])
+		assert.Equal(t, "rust", namedParamMap["filter_a_tags_0_1"])
+	})
+
+	t.Run("should render the containment without a dialect", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=a-tags-contains-golang", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND a.tags @> ARRAY[:filter_a_tags_0_0]", where)
+	})
+
+	t.Run("should reject ncontains under mysql", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+
+		_, _, _, err := builder.Build("filter=a-tags-ncontains-golang", allowed)
+		assert.NotNil(t, err)
+	})
+}
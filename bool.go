@@ -0,0 +1,62 @@
+package buildsql
+
+// BoolDialect is implemented by dialects storing booleans as numbers,
+// like the TINYINT(1) of mysql or the BIT of sql server
+type BoolDialect interface {
+	// BoolValue returns the value bound for a go bool
+	BoolValue(v bool) interface{}
+	// BoolLiteral returns the literal InlineBooleans renders for a go bool
+	BoolLiteral(v bool) string
+}
+
+func (mysql) BoolValue(v bool) interface{} {
+	return boolInt(v)
+}
+
+func (mysql) BoolLiteral(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+func (sqlServer) BoolValue(v bool) interface{} {
+	return boolInt(v)
+}
+
+func (sqlServer) BoolLiteral(v bool) string {
+	return mysql{}.BoolLiteral(v)
+}
+
+// boolInt returns 1 for true and 0 for false
+func boolInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// boolValue converts a bound go bool through the dialect, without one
+// or for dialects with a boolean type it's bound as is
+func (b *QueryBuilder) boolValue(value interface{}) interface{} {
+	v, ok := value.(bool)
+	if !ok {
+		return value
+	}
+	if dialect, ok := b.Dialect.(BoolDialect); ok {
+		return dialect.BoolValue(v)
+	}
+	return v
+}
+
+// boolLiteral renders an inlined go bool through the dialect, without
+// one the ANSI TRUE and FALSE literals are used
+func (b *QueryBuilder) boolLiteral(v bool) string {
+	if dialect, ok := b.Dialect.(BoolDialect); ok {
+		return dialect.BoolLiteral(v)
+	}
+	if v {
+		return "TRUE"
+	}
+	return "FALSE"
+}
@@ -0,0 +1,60 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderBoolDialect(t *testing.T) {
+	allowed := map[string]interface{}{"u": User{}}
+
+	for _, tc := range []struct {
+		name    string
+		dialect buildsql.Dialect
+		bound   interface{}
+		inlined string
+	}{
+		{"none", nil, true, "u.email_visibility = TRUE"},
+		{"postgres", buildsql.Postgres, true, `"u"."email_visibility" = TRUE`},
+		{"sqlite", buildsql.SQLite, true, `"u"."email_visibility" = TRUE`},
+		{"mysql", buildsql.MySQL, 1, "`u`.`email_visibility` = 1"},
+		{"sqlserver", buildsql.SQLServer, 1, "[u].[email_visibility] = 1"},
+	} {
+		t.Run("should bind a coerced bool for "+tc.name, func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder().WithDialect(tc.dialect)
+			builder.CoerceValues = true
+			_, _, namedParamMap, err := builder.Build("filter=u-email_visibility-eq-true", allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.bound, namedParamMap["filter_u_email_visibility_0"])
+		})
+
+		t.Run("should bind the boolean shorthand for "+tc.name, func(t *testing.T) {
+			_, _, namedParamMap, err := buildsql.NewQueryBuilder().WithDialect(tc.dialect).Build("filter=u-email_visibility", allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.bound, namedParamMap["filter_u_email_visibility_0"])
+		})
+
+		t.Run("should inline a bool for "+tc.name, func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder().WithDialect(tc.dialect)
+			builder.InlineBooleans = true
+			where, _, namedParamMap, err := builder.Build("filter=u-email_visibility-eq-true", allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, " AND "+tc.inlined, where)
+			assert.Equal(t, 0, len(namedParamMap))
+		})
+	}
+
+	t.Run("should bind false as 0 under mysql", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().WithDialect(buildsql.MySQL)
+		builder.InlineBooleans = true
+		where, _, _, err := builder.Build("filter=u-email_visibility-neq-false", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND `u`.`email_visibility` != 0", where)
+
+		_, _, namedParamMap, err := buildsql.NewQueryBuilder().WithDialect(buildsql.MySQL).Build("filter=u-!email_visibility", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, namedParamMap["filter_u_email_visibility_0"])
+	})
+}
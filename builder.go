@@ -3,9 +3,14 @@
 package buildsql
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -50,6 +55,9 @@ import (
 //		"amount": "pr", // price alias
 //	}
 
+// Deprecated: set QueryBuilder.Delimiter and FilterBuilder.Delimiter
+// instead; the package var is only the fallback of the builders
+// without one and will be removed in the next release
 var Delimiter string = "-"
 
 type SortDirection string
@@ -59,17 +67,131 @@ const (
 	DESC SortDirection = "DESC"
 )
 
+// MandatoryConflictPolicy decides what happens to a client filter on
+// a column that also has a mandatory filter
+type MandatoryConflictPolicy string
+
+const (
+	// MandatoryAndTogether ANDs the client filter with the mandatory one,
+	// the default; the client can only narrow the mandatory filter
+	MandatoryAndTogether MandatoryConflictPolicy = "and"
+	// MandatoryReject makes Build error
+	MandatoryReject MandatoryConflictPolicy = "reject"
+	// MandatoryClientIgnored drops the client filter
+	MandatoryClientIgnored MandatoryConflictPolicy = "ignore"
+)
+
+// Combinator joins the client filters
+type Combinator string
+
+const (
+	AndCombinator Combinator = "AND"
+	OrCombinator  Combinator = "OR"
+)
+
 type FilterField struct {
 	TableAlias string
 	FieldName  string
 	Operator   Operator
 	Value      interface{}
 	Values     []string
+
+	// TypeHint coerces the value to int, uint, float, bool, time or
+	// string regardless of the column, e.g. for function fields;
+	// parsed from a type: prefix on the value like eq-int:12345
+	TypeHint string
+
+	// boolShorthand marks a filter parsed from the alias-field
+	// or alias-!field shorthand, only valid on bool columns
+	boolShorthand bool
+
+	// shorthand marks a filter parsed from the alias-field-value
+	// shorthand with the DefaultOperator
+	shorthand bool
+
+	// preset is the name of the preset the filter was expanded from
+	preset string
+
+	// group is the path of the or:(...) and and:(...) groups the
+	// filter was parsed from, e.g. or:3.and:4
+	group string
+}
+
+// Token returns the filter param value that parses back into this filter,
+// alias-field-op-value or alias-field-op-v1,v2 for multi value operators,
+// query escaped so it can be used as filter=<token> in a url
+// the LIKE wildcards added by the parser are stripped
+func (f FilterField) Token(delimiter string) (string, error) {
+	if f.TableAlias == "" || f.FieldName == "" {
+		return "", fmt.Errorf("token: alias and field are required")
+	}
+	if strings.Contains(f.TableAlias, delimiter) || strings.Contains(f.FieldName, delimiter) {
+		return "", fmt.Errorf("token: %s.%s contains the delimiter %q", f.TableAlias, f.FieldName, delimiter)
+	}
+	if !f.Operator.IsValid() {
+		return "", fmt.Errorf("token: %q is not a valid operator", f.Operator)
+	}
+
+	hint := ""
+	if f.TypeHint != "" {
+		hint = f.TypeHint + ":"
+	}
+
+	parts := []string{f.TableAlias, f.FieldName, string(f.Operator)}
+	switch {
+	case f.Operator.IsNullary():
+	case f.Operator.IsMultiValue():
+		if (f.Operator.IsBetween() || f.Operator.IsNotBetween() || f.Operator == SimilarAbove) && len(f.Values) != 2 {
+			return "", fmt.Errorf("token: %s requires two values", f.Operator)
+		}
+		for _, v := range f.Values {
+			if strings.Contains(v, ",") {
+				return "", fmt.Errorf("token: %q contains a comma", v)
+			}
+		}
+		parts = append(parts, hint+strings.Join(f.Values, ","))
+	case f.Operator.IsLike():
+		parts = append(parts, f.Operator.unpattern(fmt.Sprint(f.Value)))
+	default:
+		parts = append(parts, hint+fmt.Sprint(f.Value))
+	}
+
+	return url.QueryEscape(strings.Join(parts, delimiter)), nil
+}
+
+// hasEmptyValue reports whether the client sent the filter without
+// a value, e.g. filter=p-name-like- from a blank form input
+// operators taking no value never have an empty value
+func (f FilterField) hasEmptyValue() bool {
+	if f.Operator.IsNullary() || f.boolShorthand {
+		return false
+	}
+	if f.Operator.IsMultiValue() {
+		for _, v := range f.Values {
+			if strings.TrimSpace(v) != "" {
+				return false
+			}
+		}
+		return true
+	}
+	value, ok := f.Value.(string)
+	if !ok {
+		return false
+	}
+	if f.Operator.IsLike() {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "%"), "%")
+	}
+	return strings.TrimSpace(value) == ""
 }
+
 type SortField struct {
 	TableAlias string
 	FieldName  string
 	Direction  SortDirection
+
+	// Position is the 1 based select column to sort on, 0 unless
+	// PositionalOrderBy parsed a sortOn like sortOn=-2
+	Position int
 }
 
 type Where struct {
@@ -77,10 +199,15 @@ type Where struct {
 	SqlString    string
 	Named        string
 	Operator     Operator
+
+	// group is the path of the or:(...) or and:(...) group of the filter
+	group string
 }
 
-func NewQueryBuilder() QueryBuilder {
-	return QueryBuilder{}
+// NewQueryBuilder returns an empty builder, configure it through its
+// fields or the chainable With setters
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{}
 }
 
 type QueryBuilder struct {
@@ -89,6 +216,289 @@ type QueryBuilder struct {
 	Filters             []FilterField
 	Sorts               []SortField
 	SearchTables        map[string]int
+	Havings             []HavingField
+	GroupBy             []string
+	Rollup              bool
+	Tables              map[string]interface{}
+	PrimaryKeys         map[string]string
+	CTEs                []CTE
+	Search              string
+	Combinator          Combinator
+	MandatoryFilters    []FilterField
+
+	// MandatoryConflictPolicy handles client filters on a column with a
+	// mandatory filter; empty means MandatoryAndTogether
+	MandatoryConflictPolicy MandatoryConflictPolicy
+
+	// ContextValues maps alias.field to a server side value, client
+	// filters on the field are rewritten to field = value so a client
+	// can't query another user's rows; see BindContextValue
+	ContextValues map[string]interface{}
+
+	// ParamNamer replaces the prefix_alias_field_i filter param names,
+	// e.g. for drivers limiting their length or charset; index is the
+	// position of the filter among those rendered by the build, multi
+	// value operators append _j, and Build errors on an invalid or
+	// colliding name
+	ParamNamer func(alias, field string, op Operator, index int) string
+
+	// paramIndex counts the filters named by the ParamNamer in a build
+	paramIndex int
+
+	// sorted are the column sorts the build wrote into the order by,
+	// the seek predicate of a keyset build compares the same columns
+	sorted []SortField
+
+	// variable records the client value params of a Compile build
+	// with their operator and coercion, nil outside of Compile
+	variable map[string]compiledParam
+
+	// StatementIndex qualifies the param names as s<index>_filter_...
+	// so the params of statements batched together never collide;
+	// 0 keeps the unqualified names
+	StatementIndex int
+
+	// Negated wraps the client conditions in NOT (...) to invert the
+	// filter set; mandatory and preset conditions are never negated
+	Negated bool
+
+	// ArrayInLists renders in and notin as col = ANY(:param) and
+	// col <> ALL(:param) binding a single slice, instead of one param per
+	// value; it requires a dialect with array columns such as Postgres
+	ArrayInLists bool
+
+	// CheapPredicatesFirst orders the conditions of each AND group so
+	// equality and IN predicates come before ranges and pattern matches,
+	// keeping the client order among predicates of the same cost
+	CheapPredicatesFirst bool
+
+	// Locking appends a row locking clause like FOR UPDATE SKIP LOCKED
+	// to BuildSelect; the zero value doesn't lock
+	Locking Locking
+
+	// DistinctOn is the alias.field list BuildSelect emits as
+	// DISTINCT ON (...), leading the order by; postgres only
+	DistinctOn []string
+
+	// InlineBooleans renders eq and neq on bool columns with the ANSI
+	// TRUE and FALSE literals instead of binding a go bool, or the 1 and
+	// 0 of a BoolDialect
+	InlineBooleans bool
+
+	// MaxSQLLength caps the length in bytes of the rendered where,
+	// bounding the output for pathological inputs; 0 means unlimited
+	MaxSQLLength int
+
+	// MaxRangeExpansion caps how many values an in list range like
+	// 1..5 on an integer column expands to; 0 means DefaultMaxRangeExpansion
+	MaxRangeExpansion int
+
+	// AllowRandomSort enables the sortOn=@random token ordering by
+	// RANDOM() or the dialect equivalent; it's expensive on big tables
+	AllowRandomSort bool
+
+	// IdentifierPattern is matched against every alias and field name
+	// before it's emitted, Build errors on a mismatch; nil means
+	// DefaultIdentifierPattern
+	IdentifierPattern *regexp.Regexp
+
+	// InferColumnFromFieldName resolves the fields missing the TagName
+	// tag by the snake_case of their go name, FirstName as first_name
+	// and UserID as user_id
+	InferColumnFromFieldName bool
+
+	// TagName is the struct tag holding the column names; empty
+	// means the sqlx 'db' tag
+	TagName string
+
+	// Delimiter separates the alias, field, operator and value of the
+	// filter, sort and having params; empty falls back to the package
+	// Delimiter, a hyphen
+	Delimiter string
+
+	// MaxLimit caps the limit of the paginators passed to Build,
+	// an unset limit included; 0 means no cap, except for the parsed
+	// Limit which is capped at DefaultMaxLimit
+	MaxLimit int
+
+	// Limit and Offset are parsed from the limit and offset params,
+	// nil when the client didn't send them; Build appends them to the
+	// order by unless a paginator is passed
+	Limit  *int
+	Offset *int
+
+	// Comment is prepended to the statements of BuildSelect and
+	// BuildDelete as a sql comment, e.g. app:orders,endpoint:list for
+	// tagging queries in the db logs
+	Comment string
+
+	// Flags map a flag name to a trusted boolean sql predicate, e.g.
+	// "overdue": "i.due_date < NOW() AND i.paid = false"; a client sending
+	// flag=overdue ANDs it in with the client filters, flag=-overdue its negation
+	Flags map[string]string
+
+	// flags are the flags toggled by the client
+	flags []flagToggle
+
+	// Presets map a preset name to trusted filters; a client sending
+	// preset=name gets them ANDed in, each field must still be allowed
+	Presets map[string][]FilterField
+
+	// MaxAliases caps how many distinct aliases the parsed filters,
+	// sorts and havings may reference, so a client can't force joins
+	// the endpoint doesn't expect; 0 means unlimited
+	MaxAliases int
+
+	// StrictAliases makes Build error when a filter or sort references an
+	// alias missing from the allowed map instead of silently dropping it
+	StrictAliases bool
+
+	// FunctionFields maps a logical alias.field to a trusted sql expression,
+	// e.g. "u.age": "age({birthdate})"; each {column} renders as a column
+	// of the alias so clients can filter on filter=u-age-gt-18
+	FunctionFields map[string]string
+
+	// AllowJSONFilters enables the compact `filters` param, a JSON array of
+	// {"alias","field","op","value"} objects parsed alongside the hyphen grammar
+	AllowJSONFilters bool
+
+	// AllowJSONSorts enables the `sort` param, a JSON array of
+	// {"alias","field","dir"} objects parsed after the sortOn params
+	AllowJSONSorts bool
+
+	// CommaSeparatedFilters splits a filter param on its commas, for
+	// clients sending filter=p-name-eq-bob,p-sku-eq-abc; the commas of an
+	// in or btw list are kept like in an or:(...) group, so a single
+	// value can't contain a comma
+	CommaSeparatedFilters bool
+
+	// SkipEmptyValues drops the client filters sent with a blank value,
+	// like filter=p-name-like- from an empty form input, instead of
+	// rendering a LIKE '%%' that matches everything; operators taking
+	// no value, like isnull, are never dropped
+	SkipEmptyValues bool
+
+	// DefaultOperator is used for the operator-less shorthand
+	// filter=alias-field-value; empty disables the shorthand
+	DefaultOperator Operator
+
+	// CoerceValues converts filter values to the go type of the
+	// reflected column (int64, float64, bool, time.Time) before binding
+	CoerceValues bool
+
+	// Dialect controls identifier quoting and, for the positional
+	// build variants, the placeholder style; nil keeps the unquoted
+	// sqlx named param output
+	Dialect Dialect
+
+	// PlaceholderStyle overrides the placeholders of BuildPositional,
+	// e.g. Postgres for $1 without quoting identifiers; nil uses the Dialect
+	PlaceholderStyle Dialect
+
+	// DisabledOperators are rejected for every field, e.g. the LIKE
+	// family on an analytics api where pattern matching is too expensive
+	DisabledOperators []Operator
+
+	// CaseInsensitiveStringCompare wraps string columns and their params
+	// in LOWER(...) for eq, neq, in and notin; other columns are untouched
+	CaseInsensitiveStringCompare bool
+
+	// RequireFilter makes Build error when no filter produced a
+	// condition, guarding expensive endpoints against full table scans
+	RequireFilter bool
+
+	// AllowUnfilteredDelete lets BuildDelete build a delete without
+	// any condition, deleting every row of the table; unlike
+	// RequireFilter the guard is on by default
+	AllowUnfilteredDelete bool
+
+	// PositionalOrderBy lets sortOn reference a BuildSelect column by
+	// its 1 based position, e.g. sortOn=-2 renders ORDER BY 2 DESC
+	PositionalOrderBy bool
+
+	// lenient collects the filters dropped by build into rejected
+	lenient  bool
+	rejected []RejectedFilter
+
+	// projection is the number of select columns positional sorts
+	// are validated against, set while BuildSelect builds
+	projection int
+
+	// unqualified renders columns without their alias, for single
+	// table statements
+	unqualified bool
+
+	// allowed are the structs and views a build validates against, the
+	// fields of its views render as their mapped columns
+	allowed map[string]interface{}
+
+	// filterable caches the declared fields of the registered models by
+	// type, nil for models that don't implement Filterable
+	filterable map[reflect.Type]map[string]Operator
+
+	// surfaces are the declared fields of the allowed aliases of a build
+	surfaces map[string]map[string]Operator
+
+	// NullSentinel is the value that makes eq render IS NULL and
+	// neq render IS NOT NULL, e.g. "null"; empty disables it
+	NullSentinel string
+
+	// FullTextColumns are the trusted, qualified columns searched by
+	// the `q` param, e.g. []string{"p.name", "p.slug"}; the Dialect
+	// must implement FullTextDialect
+	FullTextColumns []string
+
+	// ExpandBetween renders btw as an explicit comparison pair
+	// (col >= :a AND col <= :b) instead of the BETWEEN keyword
+	ExpandBetween bool
+
+	// DefaultSort is used when the client doesn't sort
+	DefaultSort []SortField
+
+	// RequireSortWithLimit makes Build error when a limit or offset is
+	// paginated without a client or default sort, since the pages of an
+	// unordered query are inconsistent
+	RequireSortWithLimit bool
+
+	// AppendDefaultSort appends the DefaultSort after the client
+	// sorts as a tiebreaker instead of dropping it
+	AppendDefaultSort bool
+
+	// ValidateBetweenOrder rejects a btw on a numeric or time column
+	// whose low bound is greater than its high bound
+	ValidateBetweenOrder bool
+}
+
+// jsonFilter is a single entry of the JSON encoded `filters` param
+// example: ?filters=[{"alias":"p","field":"name","op":"eq","value":"bob"}]
+type jsonFilter struct {
+	Alias string      `json:"alias"`
+	Field string      `json:"field"`
+	Op    Operator    `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// jsonSort is one entry of the JSON sort param
+type jsonSort struct {
+	Alias string `json:"alias"`
+	Field string `json:"field"`
+	Dir   string `json:"dir"`
+}
+
+// Reset clears the parsed filters, sorts, havings, flags, combinator,
+// search, limit and offset, keeping the configuration; the Parse methods
+// call it first, so AddHavingCount goes after them
+func (b *QueryBuilder) Reset() {
+	b.Filters = nil
+	b.Sorts = nil
+	b.Havings = nil
+	b.flags = nil
+	b.SearchTables = make(map[string]int)
+	b.Combinator = ""
+	b.Search = ""
+	b.Limit = nil
+	b.Offset = nil
+	b.rejected = nil
 }
 
 // AllowedFiltersFieldsFromMap
@@ -107,13 +517,17 @@ type QueryBuilder struct {
 //		b.AllowedFilterFields = allowed
 //	}
 func (b *QueryBuilder) ParseParamString(paramString string) error {
+	b.Reset()
+	return b.parseParamString(paramString)
+}
+
+// parseParamString parses the param string on top of the parsed state
+func (b *QueryBuilder) parseParamString(paramString string) error {
 	if paramString == "" {
 		paramString = "?"
 	}
 	// fmt.Println("paramString: ", paramString)
 
-	b.SearchTables = make(map[string]int)
-
 	if strings.Index(paramString, "?") != 0 {
 		pathParts := strings.Split(paramString, "?")
 
@@ -136,79 +550,206 @@ func (b *QueryBuilder) ParseParamString(paramString string) error {
 	q := u.Query()
 	// fmt.Println(q)
 
-	// fmt.Println("Q:", q)
+	return b.parseValues(q)
+}
 
-	// parse filters
-	if filters, ok := q["filter"]; ok {
-		var count int // Initialize count
-		for _, filter := range filters {
-			filter = strings.TrimSpace(filter)
-			parts := strings.SplitN(filter, Delimiter, 4)
+// ParseRequest parses the filters and sorts of an http request
+// GET requests are read from the query string; POST, PUT and PATCH
+// form bodies are read along with it, body values first
+// example:
+//
+//	func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+//		builder := buildsql.NewQueryBuilder()
+//		if err := builder.ParseRequest(r); err != nil {
+//			http.Error(w, err.Error(), http.StatusBadRequest)
+//			return
+//		}
+//	}
+func (b *QueryBuilder) ParseRequest(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return b.ParseValues(r.Form)
+}
 
-			if len(parts) < 3 {
-				return fmt.Errorf("filter: %s has too few params", filter)
-			}
+// ParseValues parses already decoded query params, e.g. r.URL.Query(),
+// without re-encoding them into a param string; values are used as is,
+// so they aren't unescaped a second time
+// example:
+//
+//	if err := builder.ParseValues(r.URL.Query()); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+func (b *QueryBuilder) ParseValues(q url.Values) error {
+	b.Reset()
+	return b.parseValues(q)
+}
 
-			var filterField FilterField
-			filterField.TableAlias = parts[0]
-			filterField.FieldName = parts[1]
+// parseValues parses the values on top of the parsed state
+func (b *QueryBuilder) parseValues(q url.Values) error {
+	if b.SearchTables == nil {
+		b.SearchTables = make(map[string]int)
+	}
 
-			// Handling different operator scenarios
-			operatorPart := parts[2]
-			var valuePart string
+	// parse filters
+	for index, filter := range q["filter"] {
+		filter = strings.TrimSpace(filter)
 
-			if len(parts) > 3 {
-				// Assuming the operator is one of eq, lt, gt, etc., and the next part is the value
-				filterField.Operator = Operator(operatorPart)
-				valuePart = parts[3]
+		// or:(a,b) and and:(a,b) group filters on different fields
+		filters := []string{filter}
+		if b.CommaSeparatedFilters {
+			var err error
+			if filters, err = splitGroup(filter, b.delimiter()); err != nil {
+				return fmt.Errorf("filter[%d]: %w", index, err)
+			}
+		}
+		for _, filter := range filters {
+			if err := b.parseMember(index, "", filter); err != nil {
+				return err
+			}
+		}
+	}
 
-				if filterField.Operator.IsBetween() || filterField.Operator.IsIn() || filterField.Operator.IsNotIn() {
-					sp := strings.Split(valuePart, ",")
-					filterField.Values = sp
-				}
-			} else {
-				// Handling scenarios where the operator might include the value (e.g., isnull, isnotnull)
-				if operatorPart == "isnull" || operatorPart == "isnotnull" {
-					filterField.Operator = Operator(operatorPart)
-				} else {
-					// Splitting the operator and the value
-					opAndValue := strings.SplitN(operatorPart, "-", 2)
-					if len(opAndValue) != 2 {
-						return fmt.Errorf("invalid operator and value combination: %s", operatorPart)
-					}
-					filterField.Operator = Operator(opAndValue[0])
-					valuePart = opAndValue[1]
+	// parse json filters
+	if raw, ok := q["filters"]; ok && b.AllowJSONFilters {
+		for _, encoded := range raw {
+			filterFields, err := parseJSONFilters(encoded)
+			if err != nil {
+				return err
+			}
+			for _, filterField := range filterFields {
+				b.applyNullSentinel(&filterField)
+				if err := b.checkOperator(filterField.Operator); err != nil {
+					return fmt.Errorf("filters %s.%s: %w", filterField.TableAlias, filterField.FieldName, err)
 				}
+				b.Filters = append(b.Filters, filterField)
+				b.SearchTables[filterField.TableAlias] = 1
 			}
+		}
+	}
 
-			// Assigning the value
-			if filterField.Operator.IsLike() {
-				filterField.Value = "%" + valuePart + "%"
-			} else {
-				filterField.Value = valuePart
+	// parse havings
+	if havings, ok := q["having"]; ok {
+		for _, having := range havings {
+			havingField, err := parseHaving(having, b.delimiter())
+			if err != nil {
+				return err
 			}
+			b.Havings = append(b.Havings, havingField)
+			b.SearchTables[havingField.TableAlias] = 1
+		}
+	}
 
+	// expand the presets into their registered filters
+	for _, name := range q["preset"] {
+		name = strings.TrimSpace(name)
+		preset, ok := b.Presets[name]
+		if !ok {
+			return fmt.Errorf("preset: %s is not a registered preset", name)
+		}
+		for _, filterField := range preset {
+			filterField.preset = name
 			b.Filters = append(b.Filters, filterField)
-			b.SearchTables[filterField.TableAlias] = count + 1
+			b.SearchTables[filterField.TableAlias] = 1
+		}
+	}
+
+	// toggle the registered flags, a '-' prefix negates the flag
+	for _, name := range q["flag"] {
+		name = strings.TrimSpace(name)
+		toggle := flagToggle{name: strings.TrimPrefix(name, "-"), negate: strings.HasPrefix(name, "-")}
+		if _, ok := b.Flags[toggle.name]; !ok {
+			return fmt.Errorf("flag: %s is not a registered flag", toggle.name)
 		}
+		b.flags = append(b.flags, toggle)
+	}
+
+	// parse the client combinator, an absent param keeps the
+	// current one so a restored snapshot survives the build
+	if combinator := q.Get("combinator"); combinator != "" {
+		switch Combinator(strings.ToUpper(strings.TrimSpace(combinator))) {
+		case AndCombinator:
+			b.Combinator = AndCombinator
+		case OrCombinator:
+			b.Combinator = OrCombinator
+		default:
+			return fmt.Errorf("combinator: %s is not a valid combinator", combinator)
+		}
+	}
+
+	// parse the limit and offset
+	page := Paginator{}
+	if err := page.FromQuery(q); err != nil {
+		return err
+	}
+	if strings.TrimSpace(q.Get("limit")) != "" {
+		b.Limit = &page.Limit
+	}
+	if strings.TrimSpace(q.Get("offset")) != "" {
+		b.Offset = &page.Offset
+	}
+
+	// parse the full text search
+	if _, ok := q["q"]; ok {
+		b.Search = strings.TrimSpace(q.Get("q"))
 	}
 
 	// parse sorts
 	if sortOns, ok := q["sortOn"]; ok {
+		// the optional order param sets the direction of every
+		// sort without a '-' prefix; the prefix always wins
+		defaultDir := ASC
+		if order := q.Get("order"); order != "" {
+			switch SortDirection(strings.ToUpper(strings.TrimSpace(order))) {
+			case ASC:
+			case DESC:
+				defaultDir = DESC
+			default:
+				return fmt.Errorf("order: %s is not a valid sort direction", order)
+			}
+		}
+
 		count := 0
 		for _, sort := range sortOns {
 			// check for the direction first
 			// since the delimiter is the same as the
 			// sort direction prefix
 			sort := strings.TrimSpace(sort)
-			dir := ASC
+			dir := defaultDir
 			if isDesc := strings.HasPrefix(sort, "-"); isDesc {
 				dir = DESC
 				sort = sort[1:]
 			}
 
-			parts := strings.Split(sort, Delimiter)
-			if len(parts) < 1 {
+			// relevance orders by the full text search score
+			if sort == RelevanceSort {
+				b.Sorts = append(b.Sorts, SortField{FieldName: RelevanceSort, Direction: dir})
+				continue
+			}
+
+			// random ordering scans the whole result, so it's opt in
+			if sort == RandomSort {
+				if !b.AllowRandomSort {
+					return fmt.Errorf("sortOn: %s is not allowed", RandomSort)
+				}
+				b.Sorts = append(b.Sorts, SortField{FieldName: RandomSort, Direction: dir})
+				continue
+			}
+
+			// a bare number references a select column by position
+			if b.PositionalOrderBy {
+				if position, err := strconv.Atoi(sort); err == nil {
+					if position < 1 {
+						return fmt.Errorf("sortOn: %s is not a valid column position", sort)
+					}
+					b.Sorts = append(b.Sorts, SortField{Position: position, Direction: dir})
+					continue
+				}
+			}
+
+			parts := strings.Split(sort, b.delimiter())
+			if len(parts) < 2 {
 				return fmt.Errorf("sortOn: %s has too few params", sort)
 			}
 
@@ -222,196 +763,1352 @@ func (b *QueryBuilder) ParseParamString(paramString string) error {
 		}
 	}
 
+	// parse json sorts
+	if raw, ok := q["sort"]; ok && b.AllowJSONSorts {
+		for _, encoded := range raw {
+			sortFields, err := parseJSONSorts(encoded)
+			if err != nil {
+				return err
+			}
+			for _, sortField := range sortFields {
+				b.SearchTables[sortField.TableAlias] = 1
+				b.Sorts = append(b.Sorts, sortField)
+			}
+		}
+	}
+
+	// identifiers are checked before any allowed map is consulted, so an
+	// injection attempt fails the parse even without a whitelist
+	for i, field := range b.Filters {
+		if err := b.checkIdent(field.TableAlias, field.FieldName); err != nil {
+			return fmt.Errorf("filter[%d]: %w", i, err)
+		}
+	}
+	for i, sort := range b.Sorts {
+		if sort.FieldName == RelevanceSort || sort.FieldName == RandomSort {
+			continue
+		}
+		if err := b.checkIdent(sort.TableAlias, sort.FieldName); err != nil {
+			return fmt.Errorf("sortOn[%d]: %w", i, err)
+		}
+	}
+	for i, having := range b.Havings {
+		if having.FieldName == "*" {
+			continue
+		}
+		if err := b.checkIdent(having.TableAlias, having.FieldName); err != nil {
+			return fmt.Errorf("having[%d]: %w", i, err)
+		}
+	}
+
+	if aliases := b.ReferencedAliases(); b.MaxAliases > 0 && len(aliases) > b.MaxAliases {
+		return fmt.Errorf("aliases: %s references %d aliases, the max is %d", strings.Join(aliases, ", "), len(aliases), b.MaxAliases)
+	}
+
 	// fmt.Printf("\n#%+v", b.Filters)
 	// fmt.Printf("\n#%+v\n\n", b.Sorts)
 	return nil
 }
 
-// AllowedFiltersFieldsFromReflectionMap
-// resets AllowedFilterFields
-// the map takes two fields: string key and an interface
-// the key maps to the table alias
-// the interface is a struct with 'json', 'db' tags
-// it uses reflection to determin the allowed fields
-func (b *QueryBuilder) Build(paramString string, allowed map[string]interface{}) (where string, orderBy string, namedParamMap map[string]interface{}, err error) {
-	namedParamMap = make(map[string]interface{})
-	wheres := make(map[string][]Where)
-	sb := []string{} // sort by
+// parseFilter parses a single filter token, index is the position of
+// the filter param for the errors
+func (b *QueryBuilder) parseFilter(index int, filter string) (FilterField, error) {
+	parts := strings.SplitN(filter, b.delimiter(), 4)
 
-	if err := b.ParseParamString(paramString); err != nil {
-		return "", "", nil, err
+	// boolean shorthand: alias-field means field = true
+	// and alias-!field means field = false
+	if len(parts) == 2 {
+		return FilterField{
+			TableAlias:    parts[0],
+			FieldName:     strings.TrimPrefix(parts[1], "!"),
+			Operator:      Equal,
+			Value:         !strings.HasPrefix(parts[1], "!"),
+			boolShorthand: true,
+		}, nil
 	}
 
-	fieldsByTableAlias := make(map[string][]FilterField)
-	for _, filter := range b.Filters {
-		fieldsByTableAlias[filter.FieldName] = append(fieldsByTableAlias[filter.FieldName], filter)
+	if len(parts) < 3 {
+		return FilterField{}, fmt.Errorf("filter[%d]: %q has too few params", index, filter)
 	}
 
-	sortsByTableAlias := make(map[string][]SortField)
-	for _, sort := range b.Sorts {
-		sortsByTableAlias[sort.FieldName] = append(sortsByTableAlias[sort.FieldName], sort)
-	}
+	var filterField FilterField
+	filterField.TableAlias = parts[0]
+	filterField.FieldName = parts[1]
 
-	for tableAlias, tableStruct := range allowed {
-		rv := reflect.ValueOf(tableStruct)
-		for i := 0; i < rv.NumField(); i++ {
-			tag := rv.Type().Field(i).Tag.Get("db")
-			if tag == "" {
-				continue
-			}
+	// Handling different operator scenarios
+	operatorPart := parts[2]
+	var valuePart string
 
-			fields, ok := fieldsByTableAlias[tag]
-			if ok {
-				for i, field := range fields {
-
-					if field.TableAlias == tableAlias {
-						switch field.Operator {
-						case Between:
-							// fmt.Println("field", field, "tag", tag, "tableAlias", tableAlias)
-							// fmt.Println("Values", field.Values)
-
-							if len(field.Values) == 2 {
-								namedParam0 := fmt.Sprintf("filter_%s_%s_%d_0", field.TableAlias, field.FieldName, i)
-								namedParamMap[namedParam0] = field.Values[0]
-								namedParam1 := fmt.Sprintf("filter_%s_%s_%d_1", field.TableAlias, field.FieldName, i)
-								namedParamMap[namedParam1] = field.Values[1]
-								sqlString := fmt.Sprintf("%s.%s %s :%s AND :%s", field.TableAlias, field.FieldName, field.Operator.Convert(), namedParam0, namedParam1)
-								combined := fmt.Sprintf("%s.%s", tableAlias, field.FieldName)
-								wheres[combined] = append(wheres[combined], Where{
-									CombinedName: combined,
-									SqlString:    sqlString,
-									Named:        namedParam0,
-								})
-							}
-
-						case In, NotIn:
-							var placeholders []string
-							for j, val := range field.Values {
-								namedParam := fmt.Sprintf("filter_%s_%s_%d_%d", field.TableAlias, field.FieldName, i, j)
-								namedParamMap[namedParam] = val
-								placeholders = append(placeholders, ":"+namedParam)
-							}
-							sqlString := fmt.Sprintf("%s.%s %s (%s)", field.TableAlias, field.FieldName, field.Operator.Convert(), strings.Join(placeholders, ", "))
-							combined := fmt.Sprintf("%s.%s", tableAlias, field.FieldName)
-							wheres[combined] = append(wheres[combined], Where{
-								CombinedName: combined,
-								SqlString:    sqlString,
-							})
-
-						case IsNull, IsNotNull:
-							sqlString := fmt.Sprintf("%s.%s %s", field.TableAlias, field.FieldName, field.Operator.Convert())
-							combined := fmt.Sprintf("%s.%s", tableAlias, field.FieldName)
-							wheres[combined] = append(wheres[combined], Where{
-								CombinedName: combined,
-								SqlString:    sqlString,
-							})
-
-						case Or, OrLike, OrILike:
-							namedParam := fmt.Sprintf("filter_%s_%s_%d", field.TableAlias, field.FieldName, i)
-							namedParamMap[namedParam] = field.Value
-							sqlString := fmt.Sprintf("%s.%s %s :%s", field.TableAlias, field.FieldName, field.Operator.Convert(), namedParam)
-							combined := fmt.Sprintf("%s.%s", tableAlias, field.FieldName)
-							wheres[combined] = append(wheres[combined], Where{
-								CombinedName: combined,
-								SqlString:    sqlString,
-								Named:        namedParam,
-								Operator:     field.Operator,
-							})
-
-						default:
-							namedParam := fmt.Sprintf("filter_%s_%s_%d", field.TableAlias, field.FieldName, i)
-							namedParamMap[namedParam] = field.Value
-							sqlString := fmt.Sprintf("%s.%s %s :%s", field.TableAlias, field.FieldName, field.Operator.Convert(), namedParam)
-							combined := fmt.Sprintf("%s.%s", tableAlias, field.FieldName)
-							wheres[combined] = append(wheres[combined], Where{
-								CombinedName: combined,
-								SqlString:    sqlString,
-								Named:        namedParam,
-								Operator:     field.Operator,
-							})
-						}
-					}
-				}
+	if b.DefaultOperator != "" && !Operator(operatorPart).IsValid() {
+		// shorthand without an operator: alias-field-value
+		// everything after the field name is the value
+		filterField.Operator = b.DefaultOperator
+		filterField.shorthand = true
+		valuePart = strings.Join(parts[2:], b.delimiter())
+	} else if len(parts) > 3 {
+		// Assuming the operator is one of eq, lt, gt, etc., and the next part is the value
+		filterField.Operator = Operator(operatorPart)
+		valuePart = parts[3]
+	} else {
+		// Handling scenarios where the operator takes no value (e.g., isnull, isnotnull, empty)
+		if Operator(operatorPart).IsNullary() {
+			filterField.Operator = Operator(operatorPart)
+		} else {
+			// Splitting the operator and the value
+			opAndValue := strings.SplitN(operatorPart, b.delimiter(), 2)
+			if len(opAndValue) != 2 {
+				return filterField, fmt.Errorf("filter[%d] %s.%s: %q is not a valid operator and value combination", index, parts[0], parts[1], operatorPart)
 			}
+			filterField.Operator = Operator(opAndValue[0])
+			valuePart = opAndValue[1]
+		}
+	}
 
-			sorts, ok := sortsByTableAlias[tag]
-			if ok {
-				for _, sort := range sorts {
-					if sort.TableAlias == tableAlias {
-						sb = append(sb, fmt.Sprintf("%s.%s %s", tableAlias, sort.FieldName, sort.Direction))
-					}
-				}
-			}
+	// a known type: prefix is a type hint, other
+	// prefixes like in 10:30 are part of the value
+	if hint, rest, ok := strings.Cut(valuePart, ":"); ok {
+		if isTypeHint(hint) {
+			filterField.TypeHint = hint
+			valuePart = rest
 		}
 	}
 
-	where = b.AssembledWheres(wheres)
-	orderBy = strings.Join(sb, ", ")
-	if orderBy != "" {
-		orderBy = fmt.Sprintf("ORDER BY %s", orderBy)
+	if filterField.Operator.IsMultiValue() {
+		sp := strings.Split(valuePart, ",")
+		filterField.Values = sp
 	}
 
-	return where, orderBy, namedParamMap, err
-}
+	// Assigning the value
+	if filterField.Operator.IsLike() {
+		filterField.Value = filterField.Operator.pattern(valuePart)
+	} else {
+		filterField.Value = valuePart
+	}
 
-func (b *QueryBuilder) AssembledWheres(whereMap map[string][]Where) string {
-	where := []string{}
-	orLikeWheres := []string{}
+	b.applyNullSentinel(&filterField)
+	if err := b.checkOperator(filterField.Operator); err != nil {
+		return filterField, fmt.Errorf("filter[%d] %s.%s: %w", index, filterField.TableAlias, filterField.FieldName, err)
+	}
 
-	for _, wheres := range whereMap {
-		if len(wheres) > 1 {
-			orGroup := []string{}
-			for _, w := range wheres {
-				if w.Operator == OrLike || w.Operator == OrILike {
-					orLikeWheres = append(orLikeWheres, w.SqlString)
-				} else {
-					orGroup = append(orGroup, w.SqlString)
-				}
-			}
-			if len(orGroup) > 0 {
-				where = append(where, "("+strings.Join(orGroup, " OR ")+")")
-			}
-		} else if len(wheres) == 1 {
-			if wheres[0].Operator == OrLike || wheres[0].Operator == OrILike {
-				orLikeWheres = append(orLikeWheres, wheres[0].SqlString)
-			} else {
-				where = append(where, wheres[0].SqlString)
-			}
+	return filterField, nil
+}
+
+// checkOperator rejects operators listed in DisabledOperators
+func (b *QueryBuilder) checkOperator(op Operator) error {
+	for _, disabled := range b.DisabledOperators {
+		if op == disabled {
+			return fmt.Errorf("operator %s is disabled", op)
 		}
 	}
+	return nil
+}
 
-	out := strings.Join(where, " AND ")
+// applyNullSentinel turns eq/neq against the NullSentinel
+// into isnull/isnotnull
+func (b *QueryBuilder) applyNullSentinel(filterField *FilterField) {
+	if b.NullSentinel == "" || filterField.Value != b.NullSentinel {
+		return
+	}
+	switch filterField.Operator {
+	case Equal:
+		filterField.Operator = IsNull
+		filterField.Value = ""
+	case NotEqual:
+		filterField.Operator = IsNotNull
+		filterField.Value = ""
+	}
+}
 
-	if len(orLikeWheres) > 0 {
-		if out != "" {
-			out += " AND "
-		}
-		out += "(" + strings.Join(orLikeWheres, " OR ") + ")"
+// BindContextValue binds a server side value, e.g. the current user id,
+// to alias.field; client filters on the field then compare it for equality
+// with that value, whatever operator and value the client sent, while the
+// field stays unfiltered when the client doesn't reference it
+func (b *QueryBuilder) BindContextValue(tableAlias, fieldName string, value interface{}) *QueryBuilder {
+	if b.ContextValues == nil {
+		b.ContextValues = make(map[string]interface{})
 	}
+	b.ContextValues[tableAlias+"."+fieldName] = value
+	return b
+}
 
-	if out != "" {
-		return fmt.Sprintf(" AND %s", out)
+// applyContextValue replaces a client filter on a field with a bound
+// context value by an equality check against the server value
+func (b *QueryBuilder) applyContextValue(field *FilterField) {
+	value, ok := b.ContextValues[field.TableAlias+"."+field.FieldName]
+	if !ok {
+		return
 	}
-	return ""
+	field.Operator = Equal
+	field.Value = value
+	field.Values = nil
+	field.TypeHint = ""
+	field.boolShorthand = false
 }
 
-func BuildOrderBy(on string, allowedFields map[string]string) (orderBy string, err error) {
-	if on == "" {
-		return "", nil
+// isMandatory reports whether alias.field has a mandatory filter
+func (b *QueryBuilder) isMandatory(tableAlias, fieldName string) bool {
+	for _, mandatory := range b.MandatoryFilters {
+		if mandatory.TableAlias == tableAlias && mandatory.FieldName == fieldName {
+			return true
+		}
 	}
+	return false
+}
 
-	var sb []string
-	fields := strings.Split(strings.ToLower(on), ",")
+// AddMandatoryFilter adds a trusted server side filter, e.g. a tenant id,
+// that's always ANDed at the top level regardless of the client filters
+func (b *QueryBuilder) AddMandatoryFilter(field FilterField) *QueryBuilder {
+	b.MandatoryFilters = append(b.MandatoryFilters, field)
+	return b
+}
 
-	// fmt.Println("FIELDS: ", fields)
+// parseJSONFilters decodes the JSON form of the filter param
+// the values keep their JSON types (string, float64, bool)
+// multi value operators (btw, in, notin) expect an array value
+func parseJSONFilters(encoded string) ([]FilterField, error) {
+	var entries []jsonFilter
+	if err := json.Unmarshal([]byte(encoded), &entries); err != nil {
+		return nil, fmt.Errorf("filters: invalid json: %w", err)
+	}
 
-	for _, field := range fields {
-		field = strings.TrimSpace(field)
-		dir := "ASC"
-		fieldName := field
-		if isDesc := strings.HasPrefix(field, "-"); isDesc {
-			dir = "DESC"
-			fieldName = field[1:]
+	filterFields := make([]FilterField, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Alias == "" || entry.Field == "" || entry.Op == "" {
+			return nil, fmt.Errorf("filters: alias, field and op are required")
+		}
+
+		filterField := FilterField{
+			TableAlias: entry.Alias,
+			FieldName:  entry.Field,
+			Operator:   entry.Op,
+		}
+
+		switch {
+		case entry.Op.IsMultiValue():
+			values, ok := entry.Value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("filters: %s.%s %s requires an array value", entry.Alias, entry.Field, entry.Op)
+			}
+			for _, v := range values {
+				filterField.Values = append(filterField.Values, fmt.Sprint(v))
+			}
+		case entry.Op.IsLike():
+			filterField.Value = entry.Op.pattern(entry.Value)
+		case entry.Op.IsNullary():
+		default:
+			filterField.Value = entry.Value
+		}
+
+		filterFields = append(filterFields, filterField)
+	}
+	return filterFields, nil
+}
+
+// parseJSONSorts decodes the JSON form of the sort param
+// dir is asc or desc in any case, asc when it's omitted
+func parseJSONSorts(encoded string) ([]SortField, error) {
+	var entries []jsonSort
+	if err := json.Unmarshal([]byte(encoded), &entries); err != nil {
+		return nil, fmt.Errorf("sort: invalid json: %w", err)
+	}
+
+	sortFields := make([]SortField, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Alias == "" || entry.Field == "" {
+			return nil, fmt.Errorf("sort: alias and field are required")
+		}
+
+		direction := ASC
+		switch SortDirection(strings.ToUpper(strings.TrimSpace(entry.Dir))) {
+		case "", ASC:
+		case DESC:
+			direction = DESC
+		default:
+			return nil, fmt.Errorf("sort: %s.%s: %s is not a valid sort direction", entry.Alias, entry.Field, entry.Dir)
+		}
+
+		sortFields = append(sortFields, SortField{
+			TableAlias: entry.Alias,
+			FieldName:  entry.Field,
+			Direction:  direction,
+		})
+	}
+	return sortFields, nil
+}
+
+// AllowedFiltersFieldsFromReflectionMap
+// resets AllowedFilterFields
+// the map takes two fields: string key and an interface
+// the key maps to the table alias
+// the interface is a struct with 'json', 'db' tags
+// it uses reflection to determin the allowed fields
+// the param string is parsed into a copy of the builder, so a configured
+// builder is safe to Build from many goroutines at once
+// an optional paginator's clause is appended to the order by
+func (b *QueryBuilder) Build(paramString string, allowed map[string]interface{}, paginator ...*Paginator) (where string, orderBy string, namedParamMap map[string]interface{}, err error) {
+	req, err := b.parse(paramString)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	where, orderBy, namedParamMap, err = req.build(allowed)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	orderBy, err = req.paginate(orderBy, namedParamMap, paginator)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return where, orderBy, namedParamMap, nil
+}
+
+// paginate appends the clause of the optional paginator, capped at
+// MaxLimit, to the order by and adds its params; without a paginator
+// the parsed limit and offset are used
+func (b *QueryBuilder) paginate(orderBy string, namedParamMap map[string]interface{}, paginator []*Paginator) (string, error) {
+	var page Paginator
+	switch {
+	case len(paginator) > 0 && paginator[0] != nil:
+		page = *paginator[0]
+		page.Clamp(b.MaxLimit)
+	case b.Limit != nil || b.Offset != nil:
+		page = b.parsedPage()
+	default:
+		return orderBy, nil
+	}
+	// the bound limit and offset are qualified like the filter params
+	limitName, offsetName := b.paramName("limit"), b.paramName("offset")
+	if clause := page.clause(b.Dialect, limitName, offsetName); clause != "" {
+		if b.RequireSortWithLimit && orderBy == "" {
+			return "", fmt.Errorf("pagination: a limit or offset requires a sort")
+		}
+		orderBy = strings.TrimSpace(orderBy + " " + clause)
+	}
+	for name, value := range page.params(limitName, offsetName) {
+		namedParamMap[name] = value
+	}
+	return orderBy, nil
+}
+
+// parse parses the param string into a copy of the builder
+// the receiver is only read, the copy owns the per request state
+func (b *QueryBuilder) parse(paramString string) (*QueryBuilder, error) {
+	req := *b
+	req.Filters = append([]FilterField(nil), b.Filters...)
+	req.Sorts = append([]SortField(nil), b.Sorts...)
+	req.Havings = append([]HavingField(nil), b.Havings...)
+	req.flags = append([]flagToggle(nil), b.flags...)
+	req.SearchTables = make(map[string]int, len(b.SearchTables))
+	for alias := range b.SearchTables {
+		req.SearchTables[alias] = 1
+	}
+
+	if err := req.parseParamString(paramString); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// effectiveSorts returns the client sorts, falling back to DefaultSort
+// when there are none; with AppendDefaultSort the defaults on columns
+// the client didn't sort on are appended as tiebreakers
+func (b *QueryBuilder) effectiveSorts() []SortField {
+	if len(b.Sorts) == 0 {
+		return b.DefaultSort
+	}
+	if !b.AppendDefaultSort {
+		return b.Sorts
+	}
+
+	sorts := append([]SortField{}, b.Sorts...)
+	for _, def := range b.DefaultSort {
+		sorted := false
+		for _, sort := range b.Sorts {
+			if sort.TableAlias == def.TableAlias && sort.FieldName == def.FieldName {
+				sorted = true
+				break
+			}
+		}
+		if !sorted {
+			sorts = append(sorts, def)
+		}
+	}
+	return sorts
+}
+
+// dedupeSorts drops the repeated sorts on a column, the first
+// occurrence and its direction win
+func dedupeSorts(sorts []SortField) []SortField {
+	seen := make(map[SortField]bool)
+	deduped := make([]SortField, 0, len(sorts))
+	for _, sort := range sorts {
+		key := sort
+		key.Direction = ""
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, sort)
+	}
+	return deduped
+}
+
+// checkAliases errors when a parsed filter, sort or having references
+// an alias that isn't in the allowed map
+func (b *QueryBuilder) checkAliases(allowed map[string]interface{}) error {
+	for i, field := range b.Filters {
+		if _, ok := allowed[field.TableAlias]; !ok {
+			return fmt.Errorf("filter[%d]: unknown alias %q", i, field.TableAlias)
+		}
+	}
+	for i, sort := range b.Sorts {
+		if sort.Position > 0 || (sort.TableAlias == "" && (sort.FieldName == RelevanceSort || sort.FieldName == RandomSort)) {
+			continue
+		}
+		if _, ok := allowed[sort.TableAlias]; !ok {
+			return fmt.Errorf("sortOn[%d]: unknown alias %q", i, sort.TableAlias)
+		}
+	}
+	for i, having := range b.Havings {
+		// the COUNT(*) of AddHavingCount has no alias
+		if having.TableAlias == "" && having.FieldName == "*" {
+			continue
+		}
+		if _, ok := allowed[having.TableAlias]; !ok {
+			return fmt.Errorf("having[%d]: unknown alias %q", i, having.TableAlias)
+		}
+	}
+	return nil
+}
+
+// ReferencedAliases returns the sorted distinct aliases referenced by the
+// parsed filters, sorts and havings, e.g. to decide which tables to join
+func (b *QueryBuilder) ReferencedAliases() []string {
+	seen := make(map[string]bool)
+	for _, field := range b.Filters {
+		seen[field.TableAlias] = true
+	}
+	for _, sort := range b.Sorts {
+		seen[sort.TableAlias] = true
+	}
+	for _, having := range b.Havings {
+		seen[having.TableAlias] = true
+	}
+	delete(seen, "")
+
+	aliases := make([]string, 0, len(seen))
+	for alias := range seen {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// AndFragment ANDs the conditions of the param string into an existing
+// trusted where clause: the existing clause is parenthesized and
+// followed by " AND (conditions)", so a top level OR on either side
+// can't change the meaning of the other; the existing clause is
+// returned unchanged without conditions, and without an existing
+// clause the bare " AND (conditions)" fragment is returned
+// example:
+//
+//	clause, namedParamMap, err := builder.AndFragment("a = 1 OR b = 2", "filter=p-id-eq-1", allowed)
+//	// (a = 1 OR b = 2) AND (p.id = :filter_p_id_0)
+func (b *QueryBuilder) AndFragment(existing string, paramString string, allowed map[string]interface{}) (string, map[string]interface{}, error) {
+	where, _, namedParamMap, err := b.Build(paramString, allowed)
+	if err != nil {
+		return "", nil, err
+	}
+
+	existing = strings.TrimSpace(existing)
+	conditions := strings.TrimPrefix(where, " AND ")
+	if conditions == "" {
+		return existing, namedParamMap, nil
+	}
+	if !parenthesized(conditions) {
+		conditions = "(" + conditions + ")"
+	}
+	if existing == "" {
+		return " AND " + conditions, namedParamMap, nil
+	}
+	if !parenthesized(existing) {
+		existing = "(" + existing + ")"
+	}
+	return existing + " AND " + conditions, namedParamMap, nil
+}
+
+// parenthesized reports whether the whole condition is wrapped in one
+// pair of parentheses, skipping those inside quoted literals
+func parenthesized(condition string) bool {
+	if !strings.HasPrefix(condition, "(") || !strings.HasSuffix(condition, ")") {
+		return false
+	}
+	depth, quoted := 0, false
+	for i, r := range condition {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 && i < len(condition)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// QueryShape returns the where and order by of the parsed filters and
+// sorts with placeholders but no values; requests that only differ in
+// their values share a shape, so it can key a prepared statement cache
+// call it after ParseParamString; it builds a copy, so the builder
+// isn't touched
+func (b *QueryBuilder) QueryShape(allowed map[string]interface{}) (string, error) {
+	req, err := b.parse("")
+	if err != nil {
+		return "", err
+	}
+	where, orderBy, _, err := req.build(allowed)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(where, " AND ") + " " + orderBy), nil
+}
+
+// build renders the parsed filters and sorts
+func (b *QueryBuilder) build(allowed map[string]interface{}) (where string, orderBy string, namedParamMap map[string]interface{}, err error) {
+	allowed = b.allowedTables(allowed)
+	b.allowed = allowed
+	b.surfaces = b.filterableSurfaces(allowed)
+	b.paramIndex = 0
+
+	if b.StrictAliases {
+		if err := b.checkAliases(allowed); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	namedParamMap = make(map[string]interface{})
+	wheres := newWhereSet()
+	sb := []string{} // sort by
+
+	// filters on aliases or fields that aren't allowed are dropped
+	resolve := func(field FilterField) (reflect.Type, bool) {
+		if b.isFunctionField(field.TableAlias, field.FieldName) {
+			_, ok := allowed[field.TableAlias]
+			return nil, ok
+		}
+		structField, ok := b.lookupField(allowed, field.TableAlias, field.FieldName)
+		return structField.Type, ok && fieldAllows(structField, "filter") && b.isFilterable(field.TableAlias, field.FieldName)
+	}
+	clientFilters := []FilterField{}
+	presetFilters := []FilterField{}
+	for index, field := range b.Filters {
+		if field.preset == "" {
+			if b.SkipEmptyValues && field.hasEmptyValue() {
+				continue
+			}
+			if b.isMandatory(field.TableAlias, field.FieldName) {
+				switch b.MandatoryConflictPolicy {
+				case MandatoryReject:
+					return "", "", nil, fmt.Errorf("filter[%d] %s.%s: the field has a mandatory filter", index, field.TableAlias, field.FieldName)
+				case MandatoryClientIgnored:
+					continue
+				}
+			}
+			b.applyFilterableOperator(&field)
+			b.applyContextValue(&field)
+			if b.lenient {
+				if err := b.reject(index, field, allowed, resolve); err != nil {
+					return "", "", nil, err
+				}
+			}
+			clientFilters = append(clientFilters, field)
+			continue
+		}
+		if _, ok := resolve(field); !ok {
+			return "", "", nil, fmt.Errorf("preset %s: %s.%s is not an allowed field", field.preset, field.TableAlias, field.FieldName)
+		}
+		presetFilters = append(presetFilters, field)
+	}
+	if err := b.renderFilters("filter", clientFilters, resolve, wheres, namedParamMap); err != nil {
+		return "", "", nil, err
+	}
+
+	// preset filters are ANDed in like the mandatory filters
+	presets := newWhereSet()
+	if err := b.renderFilters("preset", presetFilters, resolve, presets, namedParamMap); err != nil {
+		return "", "", nil, err
+	}
+
+	// mandatory filters are trusted server side filters, they're
+	// rendered even when their alias isn't in the allowed map
+	mandatory := newWhereSet()
+	resolveMandatory := func(field FilterField) (reflect.Type, bool) {
+		structField, ok := b.lookupField(allowed, field.TableAlias, field.FieldName)
+		if !ok {
+			return nil, true
+		}
+		return structField.Type, true
+	}
+	if err := b.renderFilters("mandatory", b.MandatoryFilters, resolveMandatory, mandatory, namedParamMap); err != nil {
+		return "", "", nil, err
+	}
+
+	for i, toggle := range b.flags {
+		wheres.add(Where{
+			CombinedName: fmt.Sprintf("flag[%d]", i),
+			SqlString:    toggle.render(b.Flags[toggle.name]),
+		})
+	}
+
+	if b.Search != "" && len(b.FullTextColumns) > 0 {
+		match, err := b.fullTextMatch()
+		if err != nil {
+			return "", "", nil, err
+		}
+		namedParamMap[b.paramName(searchParam)] = b.Search
+		wheres.add(Where{
+			CombinedName: searchParam,
+			SqlString:    match,
+			Named:        b.paramName(searchParam),
+		})
+	}
+
+	// sorts keep the order the client sent them in
+	b.sorted = nil
+	for _, sort := range dedupeSorts(b.effectiveSorts()) {
+		if sort.Position > 0 {
+			if sort.Position > b.projection {
+				return "", "", nil, fmt.Errorf("sortOn: position %d is outside the %d selected columns", sort.Position, b.projection)
+			}
+			sb = append(sb, fmt.Sprintf("%d %s", sort.Position, sort.Direction))
+			continue
+		}
+		if sort.TableAlias == "" && sort.FieldName == RelevanceSort {
+			rank, err := b.fullTextRank()
+			if err != nil {
+				return "", "", nil, err
+			}
+			sb = append(sb, fmt.Sprintf("%s %s", rank, sort.Direction))
+			continue
+		}
+		if sort.TableAlias == "" && sort.FieldName == RandomSort {
+			random, err := b.randomOrder()
+			if err != nil {
+				return "", "", nil, err
+			}
+			sb = append(sb, random)
+			continue
+		}
+		if structField, ok := b.lookupField(allowed, sort.TableAlias, sort.FieldName); !ok || !fieldAllows(structField, "sort") {
+			continue
+		}
+		if err := b.checkIdent(sort.TableAlias, sort.FieldName); err != nil {
+			return "", "", nil, fmt.Errorf("sortOn: %w", err)
+		}
+		sb = append(sb, fmt.Sprintf("%s %s", b.column(sort.TableAlias, sort.FieldName), sort.Direction))
+		b.sorted = append(b.sorted, sort)
+	}
+
+	if b.RequireFilter && len(wheres.keys)+len(presets.keys) == 0 {
+		return "", "", nil, fmt.Errorf("at least one filter is required")
+	}
+
+	where = b.joinWheres(
+		append(
+			b.assembleConditions(mandatory.keys, mandatory.wheres),
+			b.assembleConditions(presets.keys, presets.wheres)...,
+		),
+		b.assembleConditions(wheres.keys, wheres.wheres),
+	)
+	if b.MaxSQLLength > 0 && len(where) > b.MaxSQLLength {
+		return "", "", nil, fmt.Errorf("where: %d bytes exceeds the %d byte maximum", len(where), b.MaxSQLLength)
+	}
+	orderBy = strings.Join(sb, ", ")
+	if orderBy != "" {
+		orderBy = fmt.Sprintf("ORDER BY %s", orderBy)
+	}
+
+	return where, orderBy, namedParamMap, err
+}
+
+// allowedTables returns the allowed map, a nil one falls back to the
+// registered tables
+func (b *QueryBuilder) allowedTables(allowed map[string]interface{}) map[string]interface{} {
+	if allowed == nil {
+		return b.Tables
+	}
+	return allowed
+}
+
+// paramName qualifies a param name with the StatementIndex
+func (b *QueryBuilder) paramName(name string) string {
+	if b.StatementIndex == 0 {
+		return name
+	}
+	return fmt.Sprintf("s%d_%s", b.StatementIndex, name)
+}
+
+// renderFilters renders the filters into wheres and their params
+// the params are named prefix_alias_field_i
+// resolve returns the go type of the filtered column, nil when it's
+// unknown, and false when the filter must be dropped
+func (b *QueryBuilder) renderFilters(prefix string, filters []FilterField, resolve func(FilterField) (reflect.Type, bool), wheres *whereSet, namedParamMap map[string]interface{}) error {
+	// i is the index of the filter among the filters on the same alias.field
+	// so a param name only depends on the filters for that column
+	fieldCounts := make(map[string]int)
+	// owners maps each default param name to its alias.field, since
+	// p + a_b and p_a + b both name filter_p_a_b_0
+	owners := make(map[string]string)
+	for filterIndex, field := range filters {
+		if !field.Operator.IsValid() {
+			return fmt.Errorf("filter[%d] %s.%s: %q is not a valid operator", filterIndex, field.TableAlias, field.FieldName, field.Operator)
+		}
+		if err := b.checkOperator(field.Operator); err != nil {
+			return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+		}
+
+		columnType, ok := resolve(field)
+		if !ok {
+			continue
+		}
+		if err := b.checkIdent(field.TableAlias, field.FieldName); err != nil {
+			return fmt.Errorf("filter[%d]: %w", filterIndex, err)
+		}
+		if field.boolShorthand && columnType != nil && !isBoolType(columnType) {
+			return fmt.Errorf("filter[%d] %s.%s: boolean shorthand requires a bool column", filterIndex, field.TableAlias, field.FieldName)
+		}
+
+		combined := fmt.Sprintf("%s.%s", field.TableAlias, field.FieldName)
+		i := fieldCounts[combined]
+		fieldCounts[combined]++
+		if field.group != "" {
+			// the members of a group share its where key
+			combined = groupKey(field.group)
+		}
+		baseParam := b.paramName(fmt.Sprintf("%s_%s_%s_%d", prefix, field.TableAlias, field.FieldName, i))
+		// a name taken by another column gets a uniqueness suffix
+		owner := field.TableAlias + "." + field.FieldName
+		for n, name := 1, baseParam; ; n++ {
+			taker, taken := owners[name]
+			if _, bound := namedParamMap[name]; !bound && (!taken || taker == owner) {
+				baseParam = name
+				break
+			}
+			name = fmt.Sprintf("%s_%d", baseParam, n)
+		}
+		owners[baseParam] = owner
+		if b.ParamNamer != nil {
+			name := b.ParamNamer(field.TableAlias, field.FieldName, field.Operator, b.paramIndex)
+			b.paramIndex++
+			if !paramNamePattern.MatchString(name) {
+				return fmt.Errorf("filter[%d] %s.%s: %q is not a valid param name", filterIndex, field.TableAlias, field.FieldName, name)
+			}
+			baseParam = b.paramName(name)
+		}
+
+		// add tags the where with the group path of the filter
+		add := func(w Where) {
+			w.group = field.group
+			wheres.add(w)
+		}
+
+		// the value of a context bound filter comes from the server
+		_, contextBound := b.ContextValues[field.TableAlias+"."+field.FieldName]
+		// the client values of a Compile build vary, the others are fixed
+		variable := b.variable != nil && prefix == "filter" && !contextBound
+
+		// coerceType is the type bind converts the values to, nil when
+		// they're bound as they are
+		var coerceType reflect.Type
+
+		// set adds a param, a name that's already bound means the
+		// ParamNamer returned colliding names
+		set := func(namedParam string, value interface{}) error {
+			if _, ok := namedParamMap[namedParam]; ok {
+				return fmt.Errorf("filter[%d] %s.%s: the param name %s is already bound", filterIndex, field.TableAlias, field.FieldName, namedParam)
+			}
+			namedParamMap[namedParam] = value
+			if variable {
+				b.variable[namedParam] = compiledParam{operator: field.Operator, coerceType: coerceType}
+			}
+			return nil
+		}
+
+		// bind coerces the raw value to the column type when enabled
+		// and reports errors with the filter index and field path
+		if field.TypeHint != "" {
+			if !isTypeHint(field.TypeHint) {
+				return fmt.Errorf("filter[%d] %s.%s: %q is not a supported type hint", filterIndex, field.TableAlias, field.FieldName, field.TypeHint)
+			}
+			if field.Operator.IsLike() {
+				return fmt.Errorf("filter[%d] %s.%s: type hints can't be used with %s", filterIndex, field.TableAlias, field.FieldName, field.Operator)
+			}
+			if hintType, ok := typeHints[field.TypeHint]; ok {
+				columnType = hintType
+			}
+		}
+
+		if columnType != nil && (field.TypeHint != "" || b.CoerceValues && !field.Operator.IsLike()) {
+			coerceType = columnType
+		}
+		// a compiled query keeps inlined booleans as params, so Bind
+		// coerces them to the bool the build would inline
+		inlineBool := b.InlineBooleans && (field.Operator == Equal || field.Operator == NotEqual) && columnType != nil && isBoolType(columnType)
+		if inlineBool && variable {
+			coerceType = columnType
+		}
+
+		bind := func(raw interface{}) (interface{}, error) {
+			if field.TypeHint == "" && (!b.CoerceValues || field.Operator.IsLike() || columnType == nil) {
+				return b.boolValue(raw), nil
+			}
+			value, err := coerceValue(columnType, raw)
+			if err != nil {
+				return nil, fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+			}
+			return b.boolValue(value), nil
+		}
+
+		column := b.filterColumn(field.TableAlias, field.FieldName)
+
+		// placeholder renders a named param, lowered along with the
+		// column for case insensitive string equality
+		placeholder := func(namedParam string) string {
+			return ":" + namedParam
+		}
+		// ieq lowers both sides instead of ILIKE, so it's portable and
+		// a % or _ in the value is never a wildcard
+		if field.Operator == IEqual {
+			if columnType != nil && !isStringType(columnType) {
+				return fmt.Errorf("filter[%d] %s.%s: %s requires a string column", filterIndex, field.TableAlias, field.FieldName, field.Operator)
+			}
+			column = fmt.Sprintf("LOWER(%s)", column)
+			placeholder = func(namedParam string) string {
+				return fmt.Sprintf("LOWER(:%s)", namedParam)
+			}
+		}
+		if b.CaseInsensitiveStringCompare && columnType != nil && isStringType(columnType) {
+			switch field.Operator {
+			case Equal, NotEqual, In, NotIn:
+				column = fmt.Sprintf("LOWER(%s)", column)
+				placeholder = func(namedParam string) string {
+					return fmt.Sprintf("LOWER(:%s)", namedParam)
+				}
+			}
+		}
+
+		// a cast hint binds the value as text cast to its sql type
+		if _, ok := castHints[field.TypeHint]; ok {
+			switch field.Operator {
+			case Equal, NotEqual, LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual:
+			default:
+				return fmt.Errorf("filter[%d] %s.%s: the %s: cast hint can't be used with %s", filterIndex, field.TableAlias, field.FieldName, field.TypeHint, field.Operator)
+			}
+			value, err := castLiteral(field.TypeHint, field.Value)
+			if err != nil {
+				return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+			}
+			sqlString, err := b.castValue(":"+baseParam, field.TypeHint)
+			if err != nil {
+				return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+			}
+			if err := set(baseParam, value); err != nil {
+				return err
+			}
+			add(Where{
+				CombinedName: combined,
+				SqlString:    fmt.Sprintf("%s %s %s", b.filterColumn(field.TableAlias, field.FieldName), field.Operator.Convert(), sqlString),
+				Named:        baseParam,
+				Operator:     field.Operator,
+			})
+			continue
+		}
+
+		switch field.Operator {
+		case Between, NotBetween:
+			if len(field.Values) == 2 {
+				namedParam0 := baseParam + "_0"
+				namedParam1 := baseParam + "_1"
+				for j, namedParam := range []string{namedParam0, namedParam1} {
+					value, err := bind(field.Values[j])
+					if err != nil {
+						return err
+					}
+					if err := set(namedParam, value); err != nil {
+						return err
+					}
+				}
+				if b.ValidateBetweenOrder && columnType != nil {
+					if err := checkBetweenOrder(columnType, field.Values[0], field.Values[1]); err != nil {
+						return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+					}
+				}
+				sqlString := fmt.Sprintf("%s %s :%s AND :%s", column, field.Operator.Convert(), namedParam0, namedParam1)
+				if b.ExpandBetween {
+					sqlString = fmt.Sprintf("(%s >= :%s AND %s <= :%s)", column, namedParam0, column, namedParam1)
+					if field.Operator == NotBetween {
+						sqlString = fmt.Sprintf("(%s < :%s OR %s > :%s)", column, namedParam0, column, namedParam1)
+					}
+				}
+				add(Where{
+					CombinedName: combined,
+					SqlString:    sqlString,
+					Named:        namedParam0,
+					Operator:     field.Operator,
+				})
+			}
+
+		case In, NotIn:
+			inValues, err := b.expandRanges(field.Values, columnType)
+			if err != nil {
+				return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+			}
+
+			if b.ArrayInLists {
+				values := make([]interface{}, 0, len(inValues))
+				for _, val := range inValues {
+					// the array is compared against the lowered column
+					if b.CaseInsensitiveStringCompare && columnType != nil && isStringType(columnType) {
+						val = strings.ToLower(val)
+					}
+					value, err := bind(val)
+					if err != nil {
+						return err
+					}
+					values = append(values, value)
+				}
+				sqlString, err := b.arrayIn(column, ":"+baseParam, field.Operator == NotIn)
+				if err != nil {
+					return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+				}
+				if err := set(baseParam, typedSlice(values)); err != nil {
+					return err
+				}
+				add(Where{
+					CombinedName: combined,
+					SqlString:    sqlString,
+					Named:        baseParam,
+					Operator:     field.Operator,
+				})
+				continue
+			}
+
+			var placeholders []string
+			for j, val := range inValues {
+				namedParam := fmt.Sprintf("%s_%d", baseParam, j)
+				value, err := bind(val)
+				if err != nil {
+					return err
+				}
+				if err := set(namedParam, value); err != nil {
+					return err
+				}
+				placeholders = append(placeholders, placeholder(namedParam))
+			}
+			sqlString := fmt.Sprintf("%s %s (%s)", column, field.Operator.Convert(), strings.Join(placeholders, ", "))
+			add(Where{
+				CombinedName: combined,
+				SqlString:    sqlString,
+				Operator:     field.Operator,
+			})
+
+		case Contains, NotContains:
+			var placeholders []string
+			for j, val := range field.Values {
+				namedParam := fmt.Sprintf("%s_%d", baseParam, j)
+				value, err := bind(val)
+				if err != nil {
+					return err
+				}
+				if err := set(namedParam, value); err != nil {
+					return err
+				}
+				placeholders = append(placeholders, placeholder(namedParam))
+			}
+			sqlString, err := b.arrayContainsAll(column, placeholders, field.Operator == NotContains)
+			if err != nil {
+				return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+			}
+			add(Where{
+				CombinedName: combined,
+				SqlString:    sqlString,
+				Operator:     field.Operator,
+			})
+
+		case AnyEqual:
+			namedParam := baseParam
+			value, err := bind(field.Value)
+			if err != nil {
+				return err
+			}
+			sqlString, err := b.arrayContains(column, placeholder(namedParam))
+			if err != nil {
+				return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+			}
+			if err := set(namedParam, value); err != nil {
+				return err
+			}
+			add(Where{
+				CombinedName: combined,
+				SqlString:    sqlString,
+				Named:        namedParam,
+				Operator:     field.Operator,
+			})
+
+		case IsNull, IsNotNull:
+			sqlString := fmt.Sprintf("%s %s", column, field.Operator.Convert())
+			add(Where{
+				CombinedName: combined,
+				SqlString:    sqlString,
+				Operator:     field.Operator,
+			})
+
+		case IsTrue, IsFalse, IsNotTrue, IsNotFalse:
+			if columnType != nil && !isBoolType(columnType) {
+				return fmt.Errorf("filter[%d] %s.%s: %s requires a bool column", filterIndex, field.TableAlias, field.FieldName, field.Operator)
+			}
+			add(Where{
+				CombinedName: combined,
+				SqlString:    fmt.Sprintf("%s %s", column, field.Operator.Convert()),
+				Operator:     field.Operator,
+			})
+
+		case Similar, SimilarAbove:
+			if columnType != nil && !isStringType(columnType) {
+				return fmt.Errorf("filter[%d] %s.%s: %s requires a string column", filterIndex, field.TableAlias, field.FieldName, field.Operator)
+			}
+			dialect, err := b.trigram(field.Operator)
+			if err != nil {
+				return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+			}
+
+			if field.Operator == Similar {
+				if err := set(baseParam, field.Value); err != nil {
+					return err
+				}
+				add(Where{
+					CombinedName: combined,
+					SqlString:    dialect.Similar(column, placeholder(baseParam)),
+					Named:        baseParam,
+					Operator:     field.Operator,
+				})
+				continue
+			}
+
+			// similth takes the term and a threshold between 0 and 1
+			if len(field.Values) != 2 {
+				return fmt.Errorf("filter[%d] %s.%s: %s requires a term and a threshold", filterIndex, field.TableAlias, field.FieldName, field.Operator)
+			}
+			threshold, err := strconv.ParseFloat(strings.TrimSpace(field.Values[1]), 64)
+			if err != nil || threshold < 0 || threshold > 1 {
+				return fmt.Errorf("filter[%d] %s.%s: %q is not a threshold between 0 and 1", filterIndex, field.TableAlias, field.FieldName, field.Values[1])
+			}
+			term, limit := baseParam+"_0", baseParam+"_1"
+			if err := set(term, field.Values[0]); err != nil {
+				return err
+			}
+			if err := set(limit, threshold); err != nil {
+				return err
+			}
+			add(Where{
+				CombinedName: combined,
+				SqlString:    dialect.SimilarAbove(column, placeholder(term), ":"+limit),
+				Named:        term,
+				Operator:     field.Operator,
+			})
+
+		case Empty, NotEmpty:
+			// blank means NULL or the empty string
+			if columnType != nil && !isStringType(columnType) {
+				return fmt.Errorf("filter[%d] %s.%s: %s requires a string column", filterIndex, field.TableAlias, field.FieldName, field.Operator)
+			}
+			sqlString := fmt.Sprintf("(%s IS NULL OR %s = '')", column, column)
+			if field.Operator == NotEmpty {
+				sqlString = fmt.Sprintf("(%s IS NOT NULL AND %s != '')", column, column)
+			}
+			add(Where{
+				CombinedName: combined,
+				SqlString:    sqlString,
+				Operator:     field.Operator,
+			})
+
+		default:
+			// safe boolean literals are inlined instead of bound
+			if inlineBool && !variable {
+				value, err := coerceBool(strings.TrimSpace(fmt.Sprint(field.Value)))
+				if err != nil {
+					return fmt.Errorf("filter[%d] %s.%s: %w", filterIndex, field.TableAlias, field.FieldName, err)
+				}
+				add(Where{
+					CombinedName: combined,
+					SqlString:    fmt.Sprintf("%s %s %s", column, field.Operator.Convert(), b.boolLiteral(value.(bool))),
+					Operator:     field.Operator,
+				})
+				continue
+			}
+
+			// Or, OrLike and OrILike are grouped by AssembledWheres
+			namedParam := baseParam
+			value, err := bind(field.Value)
+			if err != nil {
+				return err
+			}
+			if err := set(namedParam, value); err != nil {
+				return err
+			}
+			sqlString := fmt.Sprintf("%s %s %s", column, b.operator(field.Operator), placeholder(namedParam))
+			add(Where{
+				CombinedName: combined,
+				SqlString:    sqlString,
+				Named:        namedParam,
+				Operator:     field.Operator,
+			})
+		}
+	}
+
+	return nil
+}
+
+// whereSet collects wheres by combined name in the order they first appear
+type whereSet struct {
+	keys   []string
+	wheres map[string][]Where
+}
+
+func newWhereSet() *whereSet {
+	return &whereSet{wheres: make(map[string][]Where)}
+}
+
+func (s *whereSet) add(w Where) {
+	if _, ok := s.wheres[w.CombinedName]; !ok {
+		s.keys = append(s.keys, w.CombinedName)
+	}
+	s.wheres[w.CombinedName] = append(s.wheres[w.CombinedName], w)
+}
+
+// lookupField finds the struct field with a matching TagName tag,
+// 'db' by default, on the struct registered for the table alias,
+// or the logical field of a view registered with RegisterView
+func (b *QueryBuilder) lookupField(allowed map[string]interface{}, tableAlias, fieldName string) (reflect.StructField, bool) {
+	tableStruct, ok := allowed[tableAlias]
+	if !ok || tableStruct == nil {
+		return reflect.StructField{}, false
+	}
+
+	// the logical fields of a registered view have no go type
+	if columns, ok := tableStruct.(map[string]string); ok {
+		if _, ok := columns[fieldName]; !ok {
+			return reflect.StructField{}, false
+		}
+		return reflect.StructField{Name: fieldName}, true
+	}
+
+	rt := reflect.TypeOf(tableStruct)
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		column, ok := field.Tag.Lookup(b.tagName())
+		if !ok && b.InferColumnFromFieldName && field.IsExported() {
+			column = snakeCase(field.Name)
+		}
+		if column == fieldName {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// fieldAllows reports whether the buildsql tag of the field allows the
+// capability, "filter" or "sort"; capabilities are allowed unless the
+// tag disables them, e.g. `buildsql:"filter,sort:false"`, or the tag
+// is "-", which disables both
+func fieldAllows(field reflect.StructField, capability string) bool {
+	tag, ok := field.Tag.Lookup("buildsql")
+	if !ok {
+		return true
+	}
+	if tag == "-" {
+		return false
+	}
+
+	for _, entry := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if name == capability && value != "" {
+			allowed, err := strconv.ParseBool(value)
+			return err == nil && allowed
+		}
+	}
+	return true
+}
+
+// AssembleWhere renders caller constructed filters into the AND clause
+// and its named params, with the same leading ' AND ' as Build
+// the fields are trusted: there is no allowed map to check them against
+// and an empty TableAlias renders the bare field name
+func AssembleWhere(fields []FilterField, dialect Dialect) (string, map[string]interface{}, error) {
+	b := QueryBuilder{Dialect: dialect}
+	wheres := newWhereSet()
+	namedParamMap := make(map[string]interface{})
+
+	trusted := func(FilterField) (reflect.Type, bool) {
+		return nil, true
+	}
+	if err := b.renderFilters("filter", fields, trusted, wheres, namedParamMap); err != nil {
+		return "", nil, err
+	}
+	return b.assembleWheres(wheres.keys, wheres.wheres), namedParamMap, nil
+}
+
+// AssembledWheres joins the wheres into the AND clause
+// the combined names are sorted so the output is deterministic
+func (b *QueryBuilder) AssembledWheres(whereMap map[string][]Where) string {
+	keys := make([]string, 0, len(whereMap))
+	for key := range whereMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return b.assembleWheres(keys, whereMap)
+}
+
+// assembleWheres joins the wheres in the order of the keys
+// multiple wheres on the same column are ORed together
+func (b *QueryBuilder) assembleWheres(keys []string, whereMap map[string][]Where) string {
+	return b.joinWheres(nil, b.assembleConditions(keys, whereMap))
+}
+
+// joinWheres ANDs the mandatory conditions with the client conditions
+// when the client combinator is OR, the client conditions are ORed
+// and grouped in parentheses so they can't widen the mandatory ones
+// Negated wraps only the client conditions in NOT (...)
+func (b *QueryBuilder) joinWheres(mandatory []string, client []string) string {
+	join := " AND "
+	if b.Combinator == OrCombinator {
+		join = " OR "
+	}
+	switch {
+	case b.Negated && len(client) > 0:
+		client = []string{"NOT (" + strings.Join(client, join) + ")"}
+	case b.Combinator == OrCombinator && len(client) > 1:
+		client = []string{"(" + strings.Join(client, join) + ")"}
+	}
+
+	conditions := append(append([]string{}, mandatory...), client...)
+	if len(conditions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" AND %s", strings.Join(conditions, " AND "))
+}
+
+// assembleConditions returns the top level conditions in the order of the keys
+// every predicate on one alias.field shares a key whatever its operator,
+// so several become one parenthesized OR group in the client order
+func (b *QueryBuilder) assembleConditions(keys []string, whereMap map[string][]Where) []string {
+	type condition struct {
+		sql  string
+		cost int
+	}
+	conditions := []condition{}
+	orLikeWheres := []string{}
+
+	for _, key := range keys {
+		wheres := whereMap[key]
+		if len(wheres) > 0 && wheres[0].group != "" {
+			cost := 0
+			for _, w := range wheres {
+				if c := w.Operator.cost(); c > cost {
+					cost = c
+				}
+			}
+			conditions = append(conditions, condition{renderGroup(key, wheres), cost})
+		} else if len(wheres) > 1 {
+			orGroup := []string{}
+			cost := 0
+			for _, w := range wheres {
+				if w.Operator == OrLike || w.Operator == OrILike {
+					orLikeWheres = append(orLikeWheres, w.SqlString)
+				} else {
+					orGroup = append(orGroup, w.SqlString)
+					if c := w.Operator.cost(); c > cost {
+						cost = c
+					}
+				}
+			}
+			if len(orGroup) > 0 {
+				conditions = append(conditions, condition{"(" + strings.Join(orGroup, " OR ") + ")", cost})
+			}
+		} else if len(wheres) == 1 {
+			if wheres[0].Operator == OrLike || wheres[0].Operator == OrILike {
+				orLikeWheres = append(orLikeWheres, wheres[0].SqlString)
+			} else {
+				conditions = append(conditions, condition{wheres[0].SqlString, wheres[0].Operator.cost()})
+			}
+		}
+	}
+
+	// the stable sort keeps the key order among equally cheap conditions
+	if b.CheapPredicatesFirst {
+		sort.SliceStable(conditions, func(i, j int) bool {
+			return conditions[i].cost < conditions[j].cost
+		})
+	}
+
+	where := []string{}
+	for _, c := range conditions {
+		where = append(where, c.sql)
+	}
+	if len(orLikeWheres) > 0 {
+		where = append(where, "("+strings.Join(orLikeWheres, " OR ")+")")
+	}
+	return where
+}
+
+// BuildOrderBy renders the ORDER BY of a comma separated list of
+// fields, a '-' prefix sorts descending and a :nullsfirst or :nullslast
+// suffix places the NULLs; the optional dialect renders the nulls
+// modifier, e.g. "-amount:nullslast, name"
+func BuildOrderBy(on string, allowedFields map[string]string, dialect ...Dialect) (orderBy string, err error) {
+	if on == "" {
+		return "", nil
+	}
+
+	var d Dialect
+	if len(dialect) > 0 {
+		d = dialect[0]
+	}
+
+	var sb []string
+	fields := strings.Split(strings.ToLower(on), ",")
+
+	// fmt.Println("FIELDS: ", fields)
+
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		dir := ASC
+		fieldName := field
+		if isDesc := strings.HasPrefix(field, "-"); isDesc {
+			dir = DESC
+			fieldName = field[1:]
+		}
+
+		var nulls NullsOrder
+		if name, modifier, ok := strings.Cut(fieldName, ":"); ok {
+			nulls = NullsOrder(strings.TrimSpace(modifier))
+			if nulls != NullsFirst && nulls != NullsLast {
+				return "", fmt.Errorf("error: %s is not a valid nulls modifier", modifier)
+			}
+			fieldName = strings.TrimSpace(name)
 		}
 
 		// fmt.Println("fieldName: ", fieldName)
@@ -419,7 +2116,7 @@ func BuildOrderBy(on string, allowedFields map[string]string) (orderBy string, e
 		allowed := false
 		for allowedField, tableName := range allowedFields {
 			if fieldName == allowedField {
-				sb = append(sb, fmt.Sprintf("%s.%s %s", tableName, fieldName, dir))
+				sb = append(sb, orderNulls(d, fmt.Sprintf("%s.%s", tableName, fieldName), dir, nulls))
 				allowed = true
 				break
 			}
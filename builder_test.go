@@ -2,7 +2,11 @@ package buildsql_test
 
 import (
 	"database/sql"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -123,7 +127,80 @@ func TestQueryBuilderOr(t *testing.T) {
 		expectedWhere := " AND u.id = :filter_u_id_0 AND (u.first_name LIKE :filter_u_first_name_0 OR u.last_name ILIKE :filter_u_last_name_0)"
 		assert.Equal(t, expectedWhere, where)
 	})
+
+	t.Run("should AND a single orlike with an equality", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=u-id-eq-123&filter=u-first_name-orlike-John", map[string]interface{}{
+			"u": User{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.id = :filter_u_id_0 AND (u.first_name LIKE :filter_u_first_name_0)", where)
+	})
+
+	t.Run("should join the orlike group with AND in AssembledWheres", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where := builder.AssembledWheres(map[string][]buildsql.Where{
+			"a.x": {{CombinedName: "a.x", SqlString: "a.x = :p", Named: "p", Operator: buildsql.Equal}},
+			"b.y": {{CombinedName: "b.y", SqlString: "b.y LIKE :q", Named: "q", Operator: buildsql.OrLike}},
+		})
+		assert.Equal(t, " AND a.x = :p AND (b.y LIKE :q)", where)
+	})
+
+	t.Run("should parenthesize the orlike group under the OR combinator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=u-id-eq-123&filter=u-first_name-orlike-John&filter=u-email-orlike-john&combinator=or", map[string]interface{}{
+			"u": User{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (u.id = :filter_u_id_0 OR (u.first_name LIKE :filter_u_first_name_0 OR u.email LIKE :filter_u_email_0))", where)
+	})
+}
+func TestQueryBuilderNotBetween(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+	t.Run("should render NOT BETWEEN with two params", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		assert.Nil(t, builder.ParseParamString("filter=pr-amount-nbtw-10,20"))
+		assert.Equal(t, []string{"10", "20"}, builder.Filters[0].Values)
+
+		where, _, namedParamMap, err := buildsql.NewQueryBuilder().Build("filter=pr-amount-nbtw-10,20&filter=p-id-gt-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND pr.amount NOT BETWEEN :filter_pr_amount_0_0 AND :filter_pr_amount_0_1 AND p.id > :filter_p_id_0", where)
+		assert.Equal(t, "10", namedParamMap["filter_pr_amount_0_0"])
+		assert.Equal(t, "20", namedParamMap["filter_pr_amount_0_1"])
+	})
+
+	t.Run("should expand to an OR of comparisons", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ExpandBetween = true
+
+		where, _, _, err := builder.Build("filter=pr-amount-nbtw-10,20", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (pr.amount < :filter_pr_amount_0_0 OR pr.amount > :filter_pr_amount_0_1)", where)
+	})
+
+	t.Run("should validate the bound order", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ValidateBetweenOrder = true
+
+		_, _, _, err := builder.Build("filter=pr-amount-nbtw-20,10", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should apply to a having aggregate", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("having=sum-pr-amount-nbtw-10,20"))
+
+		having, _, err := builder.BuildHaving(allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "HAVING SUM(pr.amount) NOT BETWEEN :having_sum_pr_amount_0_0 AND :having_sum_pr_amount_0_1", having)
+	})
 }
+
 func TestQueryBuilderBetween(t *testing.T) {
 	t.Run("should correctly parse a param string with between", func(t *testing.T) {
 		builder := buildsql.NewQueryBuilder()
@@ -179,3 +256,1570 @@ type User struct {
 	UpdatedAt              time.Time      `json:"updated_at" db:"updated_at" form:"updated_at"`                                              // updated_at
 
 }
+
+func TestQueryBuilderNegativeValues(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+	t.Run("should parse a negative scalar", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+
+		where, _, namedParamMap, err := builder.Build("filter=pr-amount-gt--5&filter=p-id-eq-int:-7", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND pr.amount > :filter_pr_amount_0 AND p.id = :filter_p_id_0", where)
+		assert.Equal(t, -5.0, namedParamMap["filter_pr_amount_0"])
+		assert.Equal(t, int64(-7), namedParamMap["filter_p_id_0"])
+	})
+
+	t.Run("should parse negative in elements and ranges", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+
+		_, _, namedParamMap, err := builder.Build("filter=p-id-in--1,-5..-4,3", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"filter_p_id_0_0": int64(-1),
+			"filter_p_id_0_1": int64(-5),
+			"filter_p_id_0_2": int64(-4),
+			"filter_p_id_0_3": int64(3),
+		}, namedParamMap)
+	})
+
+	t.Run("should parse a negative between low bound", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+		builder.ValidateBetweenOrder = true
+
+		where, _, namedParamMap, err := builder.Build("filter=pr-amount-btw--10,-2.5", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND pr.amount BETWEEN :filter_pr_amount_0_0 AND :filter_pr_amount_0_1", where)
+		assert.Equal(t, -10.0, namedParamMap["filter_pr_amount_0_0"])
+		assert.Equal(t, -2.5, namedParamMap["filter_pr_amount_0_1"])
+	})
+
+	t.Run("should parse a negative value in the operator-less shorthand", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultOperator = buildsql.Equal
+
+		_, _, namedParamMap, err := builder.Build("filter=pr-amount--5", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "-5", namedParamMap["filter_pr_amount_0"])
+	})
+}
+
+func TestQueryBuilderJSONFilters(t *testing.T) {
+	t.Run("should parse json filters into the Filters slice", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowJSONFilters = true
+		on := `filters=[{"alias":"p","field":"sku","op":"eq","value":"practical-cotton-gloves"},{"alias":"pr","field":"amount","op":"gte","value":9.5},{"alias":"p","field":"id","op":"in","value":[1,2,3]}]`
+
+		err := builder.ParseParamString(on)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(builder.Filters))
+		assert.Equal(t, "p", builder.Filters[0].TableAlias)
+		assert.Equal(t, "sku", builder.Filters[0].FieldName)
+		assert.Equal(t, buildsql.Equal, builder.Filters[0].Operator)
+		assert.Equal(t, "practical-cotton-gloves", builder.Filters[0].Value)
+		assert.Equal(t, "pr", builder.Filters[1].TableAlias)
+		assert.Equal(t, 9.5, builder.Filters[1].Value)
+		assert.Equal(t, buildsql.In, builder.Filters[2].Operator)
+		assert.Equal(t, []string{"1", "2", "3"}, builder.Filters[2].Values)
+	})
+
+	t.Run("should build json filters like hyphen filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowJSONFilters = true
+		on := `filters=[{"alias":"p","field":"name","op":"like","value":"Cotton"}]&filter=p-sku-eq-abc`
+
+		where, _, namedParamMap, err := builder.Build(on, map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Contains(t, where, "p.name LIKE :filter_p_name_0")
+		assert.Contains(t, where, "p.sku = :filter_p_sku_0")
+		assert.Equal(t, "%Cotton%", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should ignore json filters unless enabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		on := `filters=[{"alias":"p","field":"name","op":"eq","value":"bob"}]`
+
+		err := builder.ParseParamString(on)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(builder.Filters))
+	})
+
+	t.Run("should error on invalid json filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowJSONFilters = true
+
+		err := builder.ParseParamString(`filters=[{"alias":"p"`)
+		assert.NotNil(t, err)
+
+		err = builder.ParseParamString(`filters=[{"alias":"p","field":"id","op":"btw","value":5}]`)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderDuplicateSorts(t *testing.T) {
+	t.Run("should collapse duplicate sorts keeping the first direction", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, orderBy, _, err := builder.Build("sortOn=-p-name&sortOn=p-id&sortOn=p-name&sortOn=-p-id", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.name DESC, p.id ASC", orderBy)
+	})
+}
+
+func TestQueryBuilderJSONSorts(t *testing.T) {
+	t.Run("should parse json sorts into the Sorts slice", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowJSONSorts = true
+		on := `sort=[{"alias":"p","field":"name","dir":"asc"},{"alias":"pr","field":"amount","dir":"DESC"},{"alias":"p","field":"id"}]`
+
+		err := builder.ParseParamString(on)
+		assert.Nil(t, err)
+		assert.Equal(t, []buildsql.SortField{
+			{TableAlias: "p", FieldName: "name", Direction: buildsql.ASC},
+			{TableAlias: "pr", FieldName: "amount", Direction: buildsql.DESC},
+			{TableAlias: "p", FieldName: "id", Direction: buildsql.ASC},
+		}, builder.Sorts)
+	})
+
+	t.Run("should build json sorts after the hyphen sorts", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowJSONSorts = true
+		on := `sort=[{"alias":"pr","field":"amount","dir":"desc"}]&sortOn=p-name`
+
+		_, orderBy, _, err := builder.Build(on, map[string]interface{}{
+			"p":  Product{},
+			"pr": Pricing{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.name ASC, pr.amount DESC", orderBy)
+	})
+
+	t.Run("should reject an invalid json sort", func(t *testing.T) {
+		for _, on := range []string{
+			`sort=[{"alias":"p","field":"name","dir":"up"}]`,
+			`sort=[{"alias":"p","dir":"asc"}]`,
+			`sort={"alias":"p"`,
+		} {
+			builder := buildsql.NewQueryBuilder()
+			builder.AllowJSONSorts = true
+			assert.NotNil(t, builder.ParseParamString(on), on)
+		}
+	})
+
+	t.Run("should ignore json sorts unless enabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString(`sort=[{"alias":"p","field":"name"}]`)
+		assert.Nil(t, err)
+		assert.Empty(t, builder.Sorts)
+	})
+}
+
+func TestQueryBuilderOrderParam(t *testing.T) {
+	t.Run("should apply order=desc to a prefix-less sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString("sortOn=p-name&order=desc")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(builder.Sorts))
+		assert.Equal(t, buildsql.DESC, builder.Sorts[0].Direction)
+	})
+
+	t.Run("should let the '-' prefix win over the order param", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString("sortOn=-p-id&sortOn=p-name&order=asc")
+		assert.Nil(t, err)
+		assert.Equal(t, buildsql.DESC, builder.Sorts[0].Direction)
+		assert.Equal(t, buildsql.ASC, builder.Sorts[1].Direction)
+	})
+
+	t.Run("should build the order param into the order by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, orderBy, _, err := builder.Build("sortOn=p-name&order=DESC", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.name DESC", orderBy)
+	})
+
+	t.Run("should error on an invalid order param", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString("sortOn=p-name&order=sideways")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderDefaultOperator(t *testing.T) {
+	t.Run("should resolve the shorthand to a default eq", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultOperator = buildsql.Equal
+		where, _, namedParamMap, err := builder.Build("filter=p-sku-practical-cotton-gloves", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0", where)
+		assert.Equal(t, "practical-cotton-gloves", namedParamMap["filter_p_sku_0"])
+	})
+
+	t.Run("should resolve the shorthand to a default ilike", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultOperator = buildsql.ILike
+		where, _, namedParamMap, err := builder.Build("filter=p-name-bob", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name ILIKE :filter_p_name_0", where)
+		assert.Equal(t, "%bob%", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should keep explicit operators when a default is set", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultOperator = buildsql.ILike
+		err := builder.ParseParamString("filter=p-name-neq-bob&filter=p-name-isnull")
+		assert.Nil(t, err)
+		assert.Equal(t, buildsql.NotEqual, builder.Filters[0].Operator)
+		assert.Equal(t, "bob", builder.Filters[0].Value)
+		assert.Equal(t, buildsql.IsNull, builder.Filters[1].Operator)
+	})
+
+	t.Run("should error on the shorthand without a default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString("filter=p-name-bob")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderDisabledOperators(t *testing.T) {
+	likeFamily := []buildsql.Operator{
+		buildsql.Like, buildsql.ILike, buildsql.OrLike, buildsql.OrILike, buildsql.NotLike, buildsql.NotILike,
+	}
+
+	t.Run("should reject a disabled like filter", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DisabledOperators = likeFamily
+
+		_, _, _, err := builder.Build("filter=p-sku-eq-abc&filter=p-name-like-cotton", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, "filter[1] p.name: operator like is disabled", err.Error())
+	})
+
+	t.Run("should reject a disabled operator in json filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowJSONFilters = true
+		builder.DisabledOperators = likeFamily
+
+		err := builder.ParseParamString(`filters=[{"alias":"p","field":"name","op":"ilike","value":"x"}]`)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should allow operators that are not disabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DisabledOperators = likeFamily
+
+		where, _, _, err := builder.Build("filter=p-sku-eq-abc", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0", where)
+	})
+}
+
+func TestQueryBuilderCaseInsensitiveStringCompare(t *testing.T) {
+	t.Run("should lower string columns and leave numeric columns untouched", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CaseInsensitiveStringCompare = true
+		on := "filter=u-email-eq-Bob@Example.com&filter=u-username-in-Bob,Alice&filter=u-title-neq-Dr&filter=u-id-eq-7"
+
+		where, _, namedParamMap, err := builder.Build(on, map[string]interface{}{
+			"u": User{},
+		})
+		assert.Nil(t, err)
+		assert.Contains(t, where, "LOWER(u.email) = LOWER(:filter_u_email_0)")
+		assert.Contains(t, where, "LOWER(u.username) IN (LOWER(:filter_u_username_0_0), LOWER(:filter_u_username_0_1))")
+		assert.Contains(t, where, "LOWER(u.title) != LOWER(:filter_u_title_0)")
+		assert.Contains(t, where, "u.id = :filter_u_id_0")
+		assert.NotContains(t, where, "LOWER(u.id)")
+		assert.Equal(t, "Bob@Example.com", namedParamMap["filter_u_email_0"])
+	})
+
+	t.Run("should not lower other operators", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CaseInsensitiveStringCompare = true
+
+		where, _, _, err := builder.Build("filter=u-username-gt-m", map[string]interface{}{
+			"u": User{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.username > :filter_u_username_0", where)
+	})
+}
+
+func TestQueryBuilderDeterministicParams(t *testing.T) {
+	allowed := map[string]interface{}{
+		"p":  Product{},
+		"pr": Pricing{},
+	}
+
+	t.Run("should yield identical sql and param names for the same param string", func(t *testing.T) {
+		on := "filter=pr-amount-gt-10&filter=p-name-like-cotton&filter=p-amount-lt-5&filter=p-id-in-1,2&filter=p-name-nlike-gloves&sortOn=-pr-amount&sortOn=p-name"
+
+		first := buildsql.NewQueryBuilder()
+		where, orderBy, namedParamMap, err := first.Build(on, allowed)
+		assert.Nil(t, err)
+
+		for i := 0; i < 20; i++ {
+			builder := buildsql.NewQueryBuilder()
+			w, o, m, err := builder.Build(on, allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, where, w)
+			assert.Equal(t, orderBy, o)
+			assert.Equal(t, namedParamMap, m)
+		}
+	})
+
+	t.Run("should name params per alias and field", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		where, _, _, err := builder.Build("filter=pr-amount-gt-10&filter=p-amount-lt-5", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND pr.amount > :filter_pr_amount_0 AND p.amount < :filter_p_amount_0", where)
+	})
+
+	t.Run("should order the sorts as sent", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, orderBy, _, err := builder.Build("sortOn=-pr-amount&sortOn=p-name&sortOn=p-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY pr.amount DESC, p.name ASC, p.id ASC", orderBy)
+	})
+}
+
+func TestQueryBuilderRequireFilter(t *testing.T) {
+	allowed := map[string]interface{}{
+		"p": Product{},
+	}
+
+	t.Run("should reject an empty filter set", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.RequireFilter = true
+		_, _, _, err := builder.Build("sortOn=p-name", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject filters that are all dropped", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.RequireFilter = true
+		_, _, _, err := builder.Build("filter=x-name-eq-bob", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should allow a filtered query", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.RequireFilter = true
+		where, _, _, err := builder.Build("filter=p-name-eq-bob", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name = :filter_p_name_0", where)
+	})
+
+	t.Run("should allow an empty filter set when disabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		where, _, _, err := builder.Build("", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+	})
+}
+
+func TestQueryBuilderEmpty(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should match NULL or the empty string", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=p-sku-empty", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (p.sku IS NULL OR p.sku = '')", where)
+		assert.Empty(t, namedParamMap)
+	})
+
+	t.Run("should match neither NULL nor the empty string", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+
+		where, _, _, err := builder.Build("filter=p-sku-nempty&filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND ("p"."sku" IS NOT NULL AND "p"."sku" != '') AND "p"."id" = :filter_p_id_0`, where)
+	})
+
+	t.Run("should reject non string columns", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.Build("filter=p-amount-empty", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderSkipEmptyValues(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	on := "filter=p-name-like-&filter=p-sku-eq-%20&filter=p-id-in-&filter=p-slug-isnull&filter=p-amount-gt-10"
+
+	t.Run("should skip the filters with an empty value", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.SkipEmptyValues = true
+
+		where, _, namedParamMap, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.slug IS NULL AND p.amount > :filter_p_amount_0", where)
+		assert.Equal(t, map[string]interface{}{"filter_p_amount_0": "10"}, namedParamMap)
+	})
+
+	t.Run("should render empty values by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=p-name-like-", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name LIKE :filter_p_name_0", where)
+		assert.Equal(t, "%%", namedParamMap["filter_p_name_0"])
+	})
+}
+
+func TestQueryBuilderNullSentinel(t *testing.T) {
+	allowed := map[string]interface{}{
+		"u": User{},
+	}
+
+	t.Run("should render eq and neq against the sentinel as null checks", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.NullSentinel = "null"
+
+		where, _, namedParamMap, err := builder.Build("filter=u-title-eq-null&filter=u-avatar-neq-null&filter=u-username-eq-bob", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.title IS NULL AND u.avatar IS NOT NULL AND u.username = :filter_u_username_0", where)
+		assert.Equal(t, 1, len(namedParamMap))
+	})
+
+	t.Run("should treat the sentinel as a plain value when disabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=u-title-eq-null", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.title = :filter_u_title_0", where)
+		assert.Equal(t, "null", namedParamMap["filter_u_title_0"])
+	})
+}
+
+func TestQueryBuilderQueryShape(t *testing.T) {
+	allowed := map[string]interface{}{
+		"p":  Product{},
+		"pr": Pricing{},
+	}
+
+	t.Run("should share a shape across differing values", func(t *testing.T) {
+		first := buildsql.NewQueryBuilder()
+		err := first.ParseParamString("filter=p-name-like-cotton&filter=pr-amount-btw-1,10&sortOn=-pr-amount")
+		assert.Nil(t, err)
+		firstShape, err := first.QueryShape(allowed)
+		assert.Nil(t, err)
+
+		second := buildsql.NewQueryBuilder()
+		err = second.ParseParamString("filter=p-name-like-gloves&filter=pr-amount-btw-50,99&sortOn=-pr-amount")
+		assert.Nil(t, err)
+		secondShape, err := second.QueryShape(allowed)
+		assert.Nil(t, err)
+
+		assert.Equal(t, firstShape, secondShape)
+		assert.Equal(t, "p.name LIKE :filter_p_name_0 AND pr.amount BETWEEN :filter_pr_amount_0_0 AND :filter_pr_amount_0_1 ORDER BY pr.amount DESC", firstShape)
+	})
+
+	t.Run("should differ when the operators differ", func(t *testing.T) {
+		first := buildsql.NewQueryBuilder()
+		assert.Nil(t, first.ParseParamString("filter=p-name-eq-cotton"))
+		firstShape, err := first.QueryShape(allowed)
+		assert.Nil(t, err)
+
+		second := buildsql.NewQueryBuilder()
+		assert.Nil(t, second.ParseParamString("filter=p-name-neq-cotton"))
+		secondShape, err := second.QueryShape(allowed)
+		assert.Nil(t, err)
+
+		assert.NotEqual(t, firstShape, secondShape)
+	})
+}
+
+func TestQueryBuilderExpandBetween(t *testing.T) {
+	t.Run("should render between as a comparison pair", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ExpandBetween = true
+
+		where, _, namedParamMap, err := builder.Build("filter=pr-amount-btw-10,20", map[string]interface{}{
+			"pr": Pricing{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (pr.amount >= :filter_pr_amount_0_0 AND pr.amount <= :filter_pr_amount_0_1)", where)
+		assert.Equal(t, "10", namedParamMap["filter_pr_amount_0_0"])
+		assert.Equal(t, "20", namedParamMap["filter_pr_amount_0_1"])
+	})
+
+	t.Run("should bind the same params as the BETWEEN keyword", func(t *testing.T) {
+		on := "filter=pr-amount-btw-10,20"
+		allowed := map[string]interface{}{"pr": Pricing{}}
+
+		builder := buildsql.NewQueryBuilder()
+		where, _, namedParamMap, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND pr.amount BETWEEN :filter_pr_amount_0_0 AND :filter_pr_amount_0_1", where)
+
+		expanded := buildsql.NewQueryBuilder()
+		expanded.ExpandBetween = true
+		_, _, expandedParamMap, err := expanded.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, namedParamMap, expandedParamMap)
+	})
+}
+
+func TestQueryBuilderBoolShorthand(t *testing.T) {
+	allowed := map[string]interface{}{
+		"u": User{},
+	}
+
+	t.Run("should resolve the shorthand to true", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		where, _, namedParamMap, err := builder.Build("filter=u-verified", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.verified = :filter_u_verified_0", where)
+		assert.Equal(t, true, namedParamMap["filter_u_verified_0"])
+	})
+
+	t.Run("should resolve the negated shorthand to false", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		where, _, namedParamMap, err := builder.Build("filter=u-!require_reset", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.require_reset = :filter_u_require_reset_0", where)
+		assert.Equal(t, false, namedParamMap["filter_u_require_reset_0"])
+	})
+
+	t.Run("should reject the shorthand on a non boolean column", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, _, _, err := builder.Build("filter=u-email", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderInlineBooleans(t *testing.T) {
+	allowed := map[string]interface{}{
+		"u": User{},
+	}
+
+	t.Run("should inline the literal for a verified filter", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.InlineBooleans = true
+
+		where, _, namedParamMap, err := builder.Build("filter=u-verified-eq-true&filter=u-!require_reset&filter=u-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.verified = TRUE AND u.require_reset = FALSE AND u.id = :filter_u_id_0", where)
+		assert.Equal(t, 1, len(namedParamMap))
+	})
+
+	t.Run("should reject a value that isn't a bool", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.InlineBooleans = true
+
+		_, _, _, err := builder.Build("filter=u-verified-eq-yes'", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should bind the bool by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=u-verified-eq-true", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.verified = :filter_u_verified_0", where)
+	})
+}
+
+func TestQueryBuilderTruthOperators(t *testing.T) {
+	allowed := map[string]interface{}{
+		"u": User{},
+	}
+
+	for _, tc := range []struct {
+		operator buildsql.Operator
+		where    string
+	}{
+		{buildsql.IsTrue, " AND u.verified IS TRUE"},
+		{buildsql.IsFalse, " AND u.verified IS FALSE"},
+		{buildsql.IsNotTrue, " AND u.verified IS NOT TRUE"},
+		{buildsql.IsNotFalse, " AND u.verified IS NOT FALSE"},
+	} {
+		t.Run("should render "+tc.operator.String(), func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+
+			where, _, namedParamMap, err := builder.Build("filter=u-verified-"+tc.operator.String(), allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.where, where)
+			assert.Empty(t, namedParamMap)
+		})
+
+		t.Run("should reject "+tc.operator.String()+" on a non boolean column", func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+
+			_, _, _, err := builder.Build("filter=u-email-"+tc.operator.String(), allowed)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestQueryBuilderMandatoryFilters(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should group OR combined client filters under the mandatory filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "t", FieldName: "tenant_id", Operator: buildsql.Equal, Value: 7})
+
+		where, _, namedParamMap, err := builder.Build("filter=p-name-eq-gloves&filter=p-slug-eq-hats&combinator=or", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND t.tenant_id = :mandatory_t_tenant_id_0 AND (p.name = :filter_p_name_0 OR p.slug = :filter_p_slug_0)", where)
+		assert.Equal(t, 7, namedParamMap["mandatory_t_tenant_id_0"])
+	})
+
+	t.Run("should AND client filters by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "t", FieldName: "tenant_id", Operator: buildsql.Equal, Value: 7})
+
+		where, _, _, err := builder.Build("filter=p-name-eq-gloves&filter=p-slug-eq-hats", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND t.tenant_id = :mandatory_t_tenant_id_0 AND p.name = :filter_p_name_0 AND p.slug = :filter_p_slug_0", where)
+	})
+
+	t.Run("should render the mandatory filters without client filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "t", FieldName: "tenant_id", Operator: buildsql.Equal, Value: 7})
+
+		where, _, _, err := builder.Build("combinator=or", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND t.tenant_id = :mandatory_t_tenant_id_0", where)
+	})
+
+	t.Run("should reject an unknown combinator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, _, _, err := builder.Build("filter=p-name-eq-gloves&combinator=xor", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+type Document struct {
+	ID      int64  `db:"id"`
+	OwnerID int64  `db:"owner_id"`
+	Title   string `db:"title"`
+}
+
+func TestQueryBuilderContextValues(t *testing.T) {
+	allowed := map[string]interface{}{"d": Document{}}
+
+	t.Run("should override the client owner_id with the context value", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.BindContextValue("d", "owner_id", int64(7))
+
+		where, _, namedParamMap, err := builder.Build("filter=d-owner_id-eq-99&filter=d-title-like-tax", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND d.owner_id = :filter_d_owner_id_0 AND d.title LIKE :filter_d_title_0", where)
+		assert.Equal(t, int64(7), namedParamMap["filter_d_owner_id_0"])
+	})
+
+	t.Run("should replace the client operator with equality", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.BindContextValue("d", "owner_id", int64(7))
+
+		where, _, namedParamMap, err := builder.Build("filter=d-owner_id-in-7,8,9", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND d.owner_id = :filter_d_owner_id_0", where)
+		assert.Equal(t, map[string]interface{}{"filter_d_owner_id_0": int64(7)}, namedParamMap)
+	})
+
+	t.Run("should not filter the field when the client doesn't reference it", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.BindContextValue("d", "owner_id", int64(7))
+
+		where, _, _, err := builder.Build("filter=d-title-eq-tax", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND d.title = :filter_d_title_0", where)
+	})
+}
+
+func TestQueryBuilderStatementIndex(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should qualify the params of batched statements", func(t *testing.T) {
+		on := "filter=p-name-eq-gloves&filter=p-id-in-1,2&sortOn=p-id"
+		page := &buildsql.Paginator{Limit: 20, Offset: 40, Bind: true}
+
+		first := buildsql.NewQueryBuilder()
+		first.StatementIndex = 1
+		where1, orderBy1, params1, err := first.Build(on, allowed, page)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name = :s1_filter_p_name_0 AND p.id IN (:s1_filter_p_id_0_0, :s1_filter_p_id_0_1)", where1)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT :s1_limit OFFSET :s1_offset", orderBy1)
+
+		second := buildsql.NewQueryBuilder()
+		second.StatementIndex = 2
+		where2, orderBy2, params2, err := second.Build(on, allowed, page)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name = :s2_filter_p_name_0 AND p.id IN (:s2_filter_p_id_0_0, :s2_filter_p_id_0_1)", where2)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT :s2_limit OFFSET :s2_offset", orderBy2)
+
+		assert.Equal(t, 5, len(params1))
+		assert.Equal(t, 5, len(params2))
+		for name := range params1 {
+			_, ok := params2[name]
+			assert.False(t, ok, name)
+		}
+	})
+
+	t.Run("should keep the unqualified names by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		where, _, _, err := builder.Build("filter=p-name-eq-gloves", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name = :filter_p_name_0", where)
+	})
+}
+
+func TestQueryBuilderMaxSQLLength(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+	on := "filter=p-id-in-" + strings.Join(ids, ",")
+
+	t.Run("should reject a where longer than the maximum", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.MaxSQLLength = 4096
+
+		_, _, _, err := builder.Build(on, allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should allow a where within the maximum", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.MaxSQLLength = 4096
+
+		where, _, _, err := builder.Build("filter=p-id-in-1,2,3", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id IN (:filter_p_id_0_0, :filter_p_id_0_1, :filter_p_id_0_2)", where)
+	})
+
+	t.Run("should be unlimited by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, namedParamMap, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, 1000, len(namedParamMap))
+	})
+}
+
+func TestQueryBuilderNegated(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should negate only the client filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Negated = true
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "t", FieldName: "tenant_id", Operator: buildsql.Equal, Value: 7})
+
+		where, _, _, err := builder.Build("filter=p-name-eq-gloves&filter=p-slug-eq-hats", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND t.tenant_id = :mandatory_t_tenant_id_0 AND NOT (p.name = :filter_p_name_0 AND p.slug = :filter_p_slug_0)", where)
+	})
+
+	t.Run("should negate OR combined client filters as a group", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Negated = true
+
+		where, _, _, err := builder.Build("filter=p-name-eq-gloves&filter=p-slug-eq-hats&combinator=or", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND NOT (p.name = :filter_p_name_0 OR p.slug = :filter_p_slug_0)", where)
+	})
+
+	t.Run("should not emit NOT without client filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Negated = true
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "t", FieldName: "tenant_id", Operator: buildsql.Equal, Value: 7})
+
+		where, _, _, err := builder.Build("", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND t.tenant_id = :mandatory_t_tenant_id_0", where)
+
+		where, _, _, err = buildsql.NewQueryBuilder().WithAllowedFields(allowed).Build("sortOn=p-id", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+	})
+}
+
+func TestQueryBuilderMandatoryConflictPolicy(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	tenant := buildsql.FilterField{TableAlias: "p", FieldName: "id", Operator: buildsql.Equal, Value: 7}
+	on := "filter=p-id-neq-7&filter=p-name-eq-gloves"
+
+	t.Run("should AND the client filter with the mandatory one by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(tenant)
+
+		where, _, _, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id = :mandatory_p_id_0 AND p.id != :filter_p_id_0 AND p.name = :filter_p_name_0", where)
+	})
+
+	t.Run("should AND the client filter with MandatoryAndTogether", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(tenant)
+		builder.MandatoryConflictPolicy = buildsql.MandatoryAndTogether
+
+		where, _, _, err := builder.Build(on+"&combinator=or", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id = :mandatory_p_id_0 AND (p.id != :filter_p_id_0 OR p.name = :filter_p_name_0)", where)
+	})
+
+	t.Run("should reject the client filter with MandatoryReject", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(tenant)
+		builder.MandatoryConflictPolicy = buildsql.MandatoryReject
+
+		_, _, _, err := builder.Build(on, allowed)
+		assert.NotNil(t, err)
+
+		_, _, _, err = builder.Build("filter=p-name-eq-gloves", allowed)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should drop the client filter with MandatoryClientIgnored", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(tenant)
+		builder.MandatoryConflictPolicy = buildsql.MandatoryClientIgnored
+
+		where, _, namedParamMap, err := builder.Build(on+"&combinator=or", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id = :mandatory_p_id_0 AND p.name = :filter_p_name_0", where)
+		assert.Equal(t, 2, len(namedParamMap))
+	})
+}
+
+func TestQueryBuilderStrictAliases(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should error on a filter for an unregistered alias", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.StrictAliases = true
+
+		_, _, _, err := builder.Build("filter=x-name-eq-gloves", allowed)
+		assert.EqualError(t, err, `filter[0]: unknown alias "x"`)
+	})
+
+	t.Run("should error on a sort for an unregistered alias", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.StrictAliases = true
+
+		_, _, _, err := builder.Build("filter=p-name-eq-gloves&sortOn=-x-name", allowed)
+		assert.EqualError(t, err, `sortOn[0]: unknown alias "x"`)
+	})
+
+	t.Run("should error on a having for an unregistered alias", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.StrictAliases = true
+		builder.GroupBy = []string{"p.id"}
+		builder.AddHavingCount(buildsql.GreaterThan, 1)
+
+		_, _, err := builder.BuildSelect("product p", []string{"p.id"}, "having=sum-x-amount-gt-10", allowed)
+		assert.EqualError(t, err, `having[1]: unknown alias "x"`)
+	})
+
+	t.Run("should drop the filter when not strict", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=x-name-eq-gloves", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+	})
+}
+
+func TestQueryBuilderPresets(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+	presets := map[string][]buildsql.FilterField{
+		"high_value": {
+			{TableAlias: "pr", FieldName: "amount", Operator: buildsql.GreaterThanOrEqual, Value: 1000},
+			{TableAlias: "p", FieldName: "slug", Operator: buildsql.NotEqual, Value: "archived"},
+		},
+		"secret": {
+			{TableAlias: "p", FieldName: "password", Operator: buildsql.Equal, Value: "x"},
+		},
+	}
+
+	t.Run("should expand a preset into its filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Presets = presets
+
+		where, _, namedParamMap, err := builder.Build("preset=high_value&filter=p-name-eq-gloves&filter=p-sku-eq-g1&combinator=or", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND pr.amount >= :preset_pr_amount_0 AND p.slug != :preset_p_slug_0 AND (p.name = :filter_p_name_0 OR p.sku = :filter_p_sku_0)", where)
+		assert.Equal(t, 1000, namedParamMap["preset_pr_amount_0"])
+		assert.Equal(t, "archived", namedParamMap["preset_p_slug_0"])
+	})
+
+	t.Run("should reject an unknown preset", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Presets = presets
+
+		_, _, _, err := builder.Build("preset=low_value", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a preset field that isn't allowed", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Presets = presets
+
+		_, _, _, err := builder.Build("preset=secret", allowed)
+		assert.EqualError(t, err, "preset secret: p.password is not an allowed field")
+	})
+}
+
+func TestQueryBuilderParseRequest(t *testing.T) {
+	t.Run("should parse the query string of a GET request", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/products?filter=p-name-like-Cotton%20Gloves&sortOn=-p-id", nil)
+
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseRequest(r)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(builder.Filters))
+		assert.Equal(t, "%Cotton Gloves%", builder.Filters[0].Value)
+		assert.Equal(t, buildsql.DESC, builder.Sorts[0].Direction)
+	})
+
+	t.Run("should parse a POST form body with the query string", func(t *testing.T) {
+		body := url.Values{"filter": {"p-sku-eq-g1"}}
+		r := httptest.NewRequest(http.MethodPost, "/v1/products?filter=p-name-eq-gloves", strings.NewReader(body.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseRequest(r)
+		assert.Nil(t, err)
+
+		shape, err := builder.QueryShape(map[string]interface{}{"p": Product{}})
+		assert.Nil(t, err)
+		assert.Equal(t, "p.sku = :filter_p_sku_0 AND p.name = :filter_p_name_0", shape)
+	})
+
+	t.Run("should return parse errors", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/products?filter=p", nil)
+
+		builder := buildsql.NewQueryBuilder()
+		assert.NotNil(t, builder.ParseRequest(r))
+	})
+}
+
+func TestQueryBuilderParseValues(t *testing.T) {
+	t.Run("should parse url values with multiple filters", func(t *testing.T) {
+		q := url.Values{
+			"filter": {"p-name-like-50% cotton", "p-id-in-1,2", "p-sku-eq-a&b"},
+			"sortOn": {"-p-id"},
+		}
+
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseValues(q))
+		assert.Equal(t, 3, len(builder.Filters))
+		assert.Equal(t, "%50% cotton%", builder.Filters[0].Value)
+		assert.Equal(t, []string{"1", "2"}, builder.Filters[1].Values)
+		assert.Equal(t, "a&b", builder.Filters[2].Value)
+		assert.Equal(t, buildsql.DESC, builder.Sorts[0].Direction)
+	})
+
+	t.Run("should match ParseParamString", func(t *testing.T) {
+		q := url.Values{"filter": {"p-name-eq-gloves", "p-id-gt-3"}, "sortOn": {"p-name"}}
+		allowed := map[string]interface{}{"p": Product{}}
+
+		fromValues := buildsql.NewQueryBuilder()
+		assert.Nil(t, fromValues.ParseValues(q))
+		fromString := buildsql.NewQueryBuilder()
+		assert.Nil(t, fromString.ParseParamString(q.Encode()))
+
+		valuesShape, err := fromValues.QueryShape(allowed)
+		assert.Nil(t, err)
+		stringShape, err := fromString.QueryShape(allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, stringShape, valuesShape)
+		assert.Equal(t, fromString.Sorts, fromValues.Sorts)
+	})
+
+	t.Run("should return parse errors", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.NotNil(t, builder.ParseValues(url.Values{"filter": {"p"}}))
+	})
+}
+
+func TestQueryBuilderMaxAliases(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}, "c": Customer{}}
+
+	t.Run("should return the referenced aliases", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("filter=pr-amount-gt-1&filter=p-id-eq-1&sortOn=c-id&sortOn=-p-name&having=sum-pr-amount-gt-5"))
+		assert.Equal(t, []string{"c", "p", "pr"}, builder.ReferencedAliases())
+	})
+
+	t.Run("should reject more aliases than the max", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.MaxAliases = 2
+
+		_, _, _, err := builder.Build("filter=p-id-eq-1&filter=pr-amount-gt-1&sortOn=c-id", allowed)
+		assert.EqualError(t, err, "aliases: c, p, pr references 3 aliases, the max is 2")
+	})
+
+	t.Run("should allow up to the max", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.MaxAliases = 2
+
+		where, orderBy, _, err := builder.Build("filter=p-id-eq-1&filter=pr-amount-gt-1&sortOn=p-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id = :filter_p_id_0 AND pr.amount > :filter_pr_amount_0", where)
+		assert.Equal(t, "ORDER BY p.id ASC", orderBy)
+	})
+}
+
+// Pair has underscored columns whose default param names overlap
+// across aliases
+type Pair struct {
+	AB string `db:"a_b"`
+	B  string `db:"b"`
+}
+
+func TestQueryBuilderParamNameCollision(t *testing.T) {
+	allowed := map[string]interface{}{"p": Pair{}, "p_a": Pair{}}
+
+	t.Run("should suffix a default param name taken by another column", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=p-a_b-eq-x&filter=p_a-b-eq-y", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.a_b = :filter_p_a_b_0 AND p_a.b = :filter_p_a_b_0_1", where)
+		assert.Equal(t, map[string]interface{}{"filter_p_a_b_0": "x", "filter_p_a_b_0_1": "y"}, namedParamMap)
+	})
+
+	t.Run("should suffix the names inside a group", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=or:(p-a_b-eq-x,p_a-b-in-y,z)", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (p.a_b = :filter_p_a_b_0 OR p_a.b IN (:filter_p_a_b_0_1_0, :filter_p_a_b_0_1_1))", where)
+	})
+}
+
+func TestQueryBuilderParamNamer(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+	short := func(alias, field string, op buildsql.Operator, index int) string {
+		return "p" + strconv.Itoa(index)
+	}
+
+	t.Run("should use the custom names in the sql and the map", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ParamNamer = short
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "p", FieldName: "sku", Operator: buildsql.Equal, Value: "g1"})
+
+		where, _, namedParamMap, err := builder.Build("filter=p-id-in-1,2&filter=pr-amount-btw-10,20&filter=p-sku-neq-g2", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :p3 AND p.id IN (:p0_0, :p0_1) AND pr.amount BETWEEN :p1_0 AND :p1_1 AND p.sku != :p2", where)
+		assert.Equal(t, map[string]interface{}{
+			"p0_0": "1", "p0_1": "2",
+			"p1_0": "10", "p1_1": "20",
+			"p2": "g2",
+			"p3": "g1",
+		}, namedParamMap)
+	})
+
+	t.Run("should number every build from zero", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ParamNamer = short
+
+		for i := 0; i < 2; i++ {
+			where, _, _, err := builder.Build("filter=p-id-eq-1", allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, " AND p.id = :p0", where)
+		}
+	})
+
+	t.Run("should reject colliding names", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ParamNamer = func(alias, field string, op buildsql.Operator, index int) string {
+			return alias
+		}
+
+		_, _, _, err := builder.Build("filter=p-id-eq-1&filter=p-sku-eq-g1", allowed)
+		assert.EqualError(t, err, "filter[1] p.sku: the param name p is already bound")
+	})
+
+	t.Run("should reject invalid names", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ParamNamer = func(alias, field string, op buildsql.Operator, index int) string {
+			return alias + "-" + field
+		}
+
+		_, _, _, err := builder.Build("filter=p-id-eq-1", allowed)
+		assert.EqualError(t, err, `filter[0] p.id: "p-id" is not a valid param name`)
+	})
+}
+
+func TestQueryBuilderColumnGroups(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should OR every predicate on one column in a single group", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=p-name-eq-gloves&filter=p-id-gt-1&filter=p-name-like-cotton&filter=p-name-eq-mittens", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (p.name = :filter_p_name_0 OR p.name LIKE :filter_p_name_1 OR p.name = :filter_p_name_2) AND p.id > :filter_p_id_0", where)
+		assert.Equal(t, map[string]interface{}{
+			"filter_p_name_0": "gloves",
+			"filter_p_name_1": "%cotton%",
+			"filter_p_name_2": "mittens",
+			"filter_p_id_0":   "1",
+		}, namedParamMap)
+	})
+
+	t.Run("should render the same group on every build", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		on := "filter=p-sku-eq-a&filter=p-name-eq-gloves&filter=p-name-like-cotton&filter=p-sku-in-b,c&filter=p-name-eq-mittens"
+
+		first, _, _, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (p.sku = :filter_p_sku_0 OR p.sku IN (:filter_p_sku_1_0, :filter_p_sku_1_1)) AND (p.name = :filter_p_name_0 OR p.name LIKE :filter_p_name_1 OR p.name = :filter_p_name_2)", first)
+		for i := 0; i < 50; i++ {
+			where, _, _, err := builder.Build(on, allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, first, where)
+		}
+	})
+}
+
+type Customer struct {
+	ID       int64  `json:"id" db:"id"`
+	Email    string `json:"email" db:"email" buildsql:"filter,sort:false"`
+	Notes    string `json:"notes" db:"notes" buildsql:"filter:false"`
+	Password string `json:"-" db:"password" buildsql:"-"`
+}
+
+func TestQueryBuilderCapabilityTags(t *testing.T) {
+	allowed := map[string]interface{}{"c": Customer{}}
+
+	t.Run("should filter but not sort a non sortable field", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, _, err := builder.Build("filter=c-email-eq-a@b.c&sortOn=c-email&sortOn=-c-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND c.email = :filter_c_email_0", where)
+		assert.Equal(t, "ORDER BY c.id DESC", orderBy)
+	})
+
+	t.Run("should sort but not filter a non filterable field", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, _, err := builder.Build("filter=c-notes-eq-vip&sortOn=c-notes", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+		assert.Equal(t, "ORDER BY c.notes ASC", orderBy)
+	})
+
+	t.Run("should neither filter nor sort a field tagged -", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, _, err := builder.Build("filter=c-password-eq-x&sortOn=c-password", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+		assert.Equal(t, "", orderBy)
+	})
+}
+
+func TestQueryBuilderCheapPredicatesFirst(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+	on := "filter=p-name-like-cotton&filter=pr-amount-gte-10&filter=p-sku-eq-g1&filter=p-slug-orlike-glove&filter=p-id-in-1,2&filter=p-id-in-3"
+
+	t.Run("should order equality and in predicates first", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CheapPredicatesFirst = true
+
+		where, _, _, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0 AND (p.id IN (:filter_p_id_0_0, :filter_p_id_0_1) OR p.id IN (:filter_p_id_1_0)) AND pr.amount >= :filter_pr_amount_0 AND p.name LIKE :filter_p_name_0 AND (p.slug LIKE :filter_p_slug_0)", where)
+	})
+
+	t.Run("should keep the client order by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name LIKE :filter_p_name_0 AND pr.amount >= :filter_pr_amount_0 AND p.sku = :filter_p_sku_0 AND (p.id IN (:filter_p_id_0_0, :filter_p_id_0_1) OR p.id IN (:filter_p_id_1_0)) AND (p.slug LIKE :filter_p_slug_0)", where)
+	})
+}
+
+func TestQueryBuilderAndFragment(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should AND into an unparenthesized OR clause", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		clause, namedParamMap, err := builder.AndFragment("a = 1 OR b = 2", "filter=p-id-eq-1&filter=p-name-eq-gloves&combinator=or", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "(a = 1 OR b = 2) AND (p.id = :filter_p_id_0 OR p.name = :filter_p_name_0)", clause)
+		assert.Equal(t, "1", namedParamMap["filter_p_id_0"])
+	})
+
+	t.Run("should not parenthesize a wrapped clause twice", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		clause, _, err := builder.AndFragment("(a = 1 OR b = 2)", "filter=p-id-eq-1&filter=p-name-eq-gloves", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "(a = 1 OR b = 2) AND (p.id = :filter_p_id_0 AND p.name = :filter_p_name_0)", clause)
+	})
+
+	t.Run("should wrap a clause whose parentheses don't span it", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		clause, _, err := builder.AndFragment("(a = 1) OR (b = 2)", "filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "((a = 1) OR (b = 2)) AND (p.id = :filter_p_id_0)", clause)
+	})
+
+	t.Run("should keep two groups in one pair of parentheses", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		clause, _, err := builder.AndFragment("a = 1", "filter=or:(p-id-eq-1,p-id-eq-2)&filter=or:(p-name-eq-a,p-name-eq-b)", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "(a = 1) AND ((p.id = :filter_p_id_0 OR p.id = :filter_p_id_1) AND (p.name = :filter_p_name_0 OR p.name = :filter_p_name_1))", clause)
+	})
+
+	t.Run("should return the existing clause without conditions", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		clause, _, err := builder.AndFragment("a = 1 OR b = 2", "sortOn=p-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "a = 1 OR b = 2", clause)
+	})
+
+	t.Run("should return the fragment without an existing clause", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		clause, _, err := builder.AndFragment("", "filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (p.id = :filter_p_id_0)", clause)
+	})
+}
+
+func TestAssembleWhere(t *testing.T) {
+	t.Run("should render every operator category", func(t *testing.T) {
+		where, namedParamMap, err := buildsql.AssembleWhere([]buildsql.FilterField{
+			{TableAlias: "p", FieldName: "sku", Operator: buildsql.Equal, Value: "abc"},
+			{TableAlias: "p", FieldName: "name", Operator: buildsql.Like, Value: "%cotton%"},
+			{TableAlias: "pr", FieldName: "amount", Operator: buildsql.Between, Values: []string{"1", "9"}},
+			{TableAlias: "p", FieldName: "id", Operator: buildsql.NotIn, Values: []string{"4", "5"}},
+			{TableAlias: "p", FieldName: "slug", Operator: buildsql.IsNull},
+			{TableAlias: "u", FieldName: "first_name", Operator: buildsql.OrLike, Value: "%bob%"},
+			{TableAlias: "u", FieldName: "email", Operator: buildsql.OrLike, Value: "%bob%"},
+		}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0 AND p.name LIKE :filter_p_name_0 AND pr.amount BETWEEN :filter_pr_amount_0_0 AND :filter_pr_amount_0_1 AND p.id NOT IN (:filter_p_id_0_0, :filter_p_id_0_1) AND p.slug IS NULL AND (u.first_name LIKE :filter_u_first_name_0 OR u.email LIKE :filter_u_email_0)", where)
+		assert.Equal(t, 8, len(namedParamMap))
+		assert.Equal(t, "9", namedParamMap["filter_pr_amount_0_1"])
+	})
+
+	t.Run("should render bare fields through a dialect", func(t *testing.T) {
+		where, namedParamMap, err := buildsql.AssembleWhere([]buildsql.FilterField{
+			{FieldName: "name", Operator: buildsql.NotEqual, Value: "bob"},
+		}, buildsql.Postgres)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "name" != :filter__name_0`, where)
+		assert.Equal(t, "bob", namedParamMap["filter__name_0"])
+	})
+
+	t.Run("should reject an unknown operator", func(t *testing.T) {
+		_, _, err := buildsql.AssembleWhere([]buildsql.FilterField{
+			{TableAlias: "p", FieldName: "name", Operator: buildsql.Operator("near"), Value: "x"},
+		}, nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderDefaultSort(t *testing.T) {
+	allowed := map[string]interface{}{
+		"p": Product{},
+	}
+	defaultSort := []buildsql.SortField{
+		{TableAlias: "p", FieldName: "id", Direction: buildsql.DESC},
+	}
+
+	t.Run("should use the default sort when the client doesn't sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultSort = defaultSort
+		_, orderBy, _, err := builder.Build("", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id DESC", orderBy)
+	})
+
+	t.Run("should replace the default sort with the client sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultSort = defaultSort
+		_, orderBy, _, err := builder.Build("sortOn=p-name", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.name ASC", orderBy)
+	})
+
+	t.Run("should append the default sort after the client sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultSort = defaultSort
+		builder.AppendDefaultSort = true
+		_, orderBy, _, err := builder.Build("sortOn=p-name", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.name ASC, p.id DESC", orderBy)
+	})
+
+	t.Run("should not append a default on a column the client sorted", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultSort = defaultSort
+		builder.AppendDefaultSort = true
+		_, orderBy, _, err := builder.Build("sortOn=p-id&sortOn=p-name", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC, p.name ASC", orderBy)
+	})
+}
+
+func TestFilterFieldToken(t *testing.T) {
+	t.Run("should round trip every operator", func(t *testing.T) {
+		tokens := []string{
+			"p-name-eq-Practical Cotton Gloves",
+			"p-name-neq-bob",
+			"p-name-like-cotton",
+			"p-name-ilike-cotton",
+			"p-name-orlike-cotton",
+			"p-name-orilike-cotton",
+			"p-name-nlike-cotton",
+			"p-name-nilike-cotton",
+			"pr-amount-lt-10",
+			"pr-amount-lte-10",
+			"pr-amount-gt-10",
+			"pr-amount-gte-10",
+			"r-created_at-btw-2024-06-12 00:00:00,2024-06-12 23:59:59",
+			"p-name-or-bob",
+			"p-id-in-1,2,3",
+			"p-id-notin-4,5",
+			"p-slug-isnull",
+			"p-slug-isnotnull",
+			"p-sku-eq-a&b=c-d",
+		}
+
+		for _, token := range tokens {
+			builder := buildsql.NewQueryBuilder()
+			err := builder.ParseParamString("filter=" + url.QueryEscape(token))
+			assert.Nil(t, err, token)
+			assert.Equal(t, 1, len(builder.Filters), token)
+
+			encoded, err := builder.Filters[0].Token(buildsql.Delimiter)
+			assert.Nil(t, err, token)
+
+			decoded, err := url.QueryUnescape(encoded)
+			assert.Nil(t, err)
+			assert.Equal(t, token, decoded)
+
+			reparsed := buildsql.NewQueryBuilder()
+			err = reparsed.ParseParamString("filter=" + encoded)
+			assert.Nil(t, err, token)
+			assert.Equal(t, builder.Filters, reparsed.Filters, token)
+		}
+	})
+
+	t.Run("should reject filters that can't be tokenized", func(t *testing.T) {
+		_, err := buildsql.FilterField{TableAlias: "p", FieldName: "first-name", Operator: buildsql.Equal, Value: "x"}.Token("-")
+		assert.NotNil(t, err)
+
+		_, err = buildsql.FilterField{TableAlias: "p", FieldName: "id", Operator: buildsql.Between, Values: []string{"1"}}.Token("-")
+		assert.NotNil(t, err)
+
+		_, err = buildsql.FilterField{TableAlias: "p", FieldName: "id", Operator: buildsql.Operator("near"), Value: "x"}.Token("-")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderIEqual(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should lower the column and the param", func(t *testing.T) {
+		where, _, namedParamMap, err := buildsql.NewQueryBuilder().Build("filter=p-name-ieq-Bob@Example.com", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND LOWER(p.name) = LOWER(:filter_p_name_0)", where)
+		assert.Equal(t, "Bob@Example.com", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should bind wildcards as literal characters", func(t *testing.T) {
+		where, _, namedParamMap, err := buildsql.NewQueryBuilder().Build("filter=p-name-ieq-100%25_off", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND LOWER(p.name) = LOWER(:filter_p_name_0)", where)
+		assert.Equal(t, "100%_off", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should render the same on every dialect", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+		where, _, _, err := builder.Build("filter=p-name-ieq-bob", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND LOWER(`p`.`name`) = LOWER(:filter_p_name_0)", where)
+	})
+
+	t.Run("should reject a non string column", func(t *testing.T) {
+		_, _, _, err := buildsql.NewQueryBuilder().Build("filter=p-id-ieq-1", allowed)
+		assert.EqualError(t, err, "filter[0] p.id: ieq requires a string column")
+	})
+}
+
+func TestQueryBuilderCommaSeparatedFilters(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should parse a comma joined filter into multiple filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CommaSeparatedFilters = true
+		where, _, namedParamMap, err := builder.Build("filter=p-name-eq-bob,p-sku-eq-abc", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name = :filter_p_name_0 AND p.sku = :filter_p_sku_0", where)
+		assert.Equal(t, "bob", namedParamMap["filter_p_name_0"])
+		assert.Equal(t, "abc", namedParamMap["filter_p_sku_0"])
+	})
+
+	t.Run("should keep the commas of an in list", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CommaSeparatedFilters = true
+		where, _, _, err := builder.Build("filter=p-id-in-1,2,p-sku-isnull&filter=p-name-eq-bob", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id IN (:filter_p_id_0_0, :filter_p_id_0_1) AND p.sku IS NULL AND p.name = :filter_p_name_0", where)
+	})
+
+	t.Run("should keep the commas of a value by default", func(t *testing.T) {
+		where, _, namedParamMap, err := buildsql.NewQueryBuilder().Build("filter=p-name-eq-bob,p-sku-eq-abc", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name = :filter_p_name_0", where)
+		assert.Equal(t, "bob,p-sku-eq-abc", namedParamMap["filter_p_name_0"])
+	})
+}
+
+func TestQueryBuilderLikeStartEnd(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should match a prefix with likestart", func(t *testing.T) {
+		where, _, namedParamMap, err := buildsql.NewQueryBuilder().Build("filter=p-name-likestart-cot", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name LIKE :filter_p_name_0", where)
+		assert.Equal(t, "cot%", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should match a suffix with likeend", func(t *testing.T) {
+		where, _, namedParamMap, err := buildsql.NewQueryBuilder().Build("filter=p-name-likeend-ton", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name LIKE :filter_p_name_0", where)
+		assert.Equal(t, "%ton", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should wrap json filter values the same way", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowJSONFilters = true
+		_, _, namedParamMap, err := builder.Build(`filters=[{"alias":"p","field":"name","op":"likestart","value":"cot"}]`, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "cot%", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should round trip through the filter token", func(t *testing.T) {
+		field := buildsql.FilterField{TableAlias: "p", FieldName: "name", Operator: buildsql.LikeEnd, Value: "%ton"}
+		token, err := field.Token(buildsql.Delimiter)
+		assert.Nil(t, err)
+		assert.Equal(t, "p-name-likeend-ton", token)
+	})
+}
+
+func TestQueryBuilderDelimiter(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+	t.Run("should split filters, sorts and havings on the builder delimiter", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().WithDelimiter(".")
+		where, orderBy, namedParamMap, err := builder.Build("filter=p.sku.eq.gloves-xl&filter=p.id.in.1,2&sortOn=-p.name", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0 AND p.id IN (:filter_p_id_0_0, :filter_p_id_0_1)", where)
+		assert.Equal(t, "ORDER BY p.name DESC", orderBy)
+		assert.Equal(t, "gloves-xl", namedParamMap["filter_p_sku_0"])
+
+		parsed := buildsql.NewQueryBuilder().WithDelimiter(".")
+		parsed.GroupBy = []string{"p.id"}
+		assert.Nil(t, parsed.ParseParamString("having=sum.pr.amount.gt.100"))
+		having, _, err := parsed.BuildHaving(allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "HAVING SUM(pr.amount) > :having_sum_pr_amount_0", having)
+	})
+
+	t.Run("should split or groups on the builder delimiter", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().WithDelimiter(".")
+		where, _, _, err := builder.Build("filter=or:(p.id.in.1,2,p.sku.isnull)", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (p.id IN (:filter_p_id_0_0, :filter_p_id_0_1) OR p.sku IS NULL)", where)
+	})
+
+	t.Run("should keep the package delimiter without one", func(t *testing.T) {
+		where, _, _, err := buildsql.NewQueryBuilder().Build("filter=p-sku-eq-gloves", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0", where)
+	})
+
+	t.Run("should round trip a filter builder with the same delimiter", func(t *testing.T) {
+		fb := buildsql.NewFilterBuilder()
+		fb.Delimiter = "."
+		fb.AddFilter("p", "sku", buildsql.Equal, "gloves-xl").AddSort("p", "name", buildsql.DESC)
+		assert.Equal(t, "filter=p.sku.eq.gloves-xl&sortOn=-p.name", fb.String())
+
+		builder := buildsql.NewQueryBuilder().WithDelimiter(".")
+		assert.Nil(t, builder.ParseParamString(fb.String()))
+		assert.Equal(t, "gloves-xl", builder.Filters[0].Value)
+	})
+}
+
+func TestQueryBuilderReset(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should not leak filters and sorts between parses", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("filter=p-name-eq-bob&sortOn=p-id&having=sum-p-amount-gt-1&combinator=or&q=gloves&limit=5"))
+		assert.Nil(t, builder.ParseParamString("filter=p-sku-eq-abc&sortOn=-p-name"))
+
+		assert.Equal(t, 1, len(builder.Filters))
+		assert.Equal(t, "sku", builder.Filters[0].FieldName)
+		assert.Equal(t, []buildsql.SortField{{TableAlias: "p", FieldName: "name", Direction: buildsql.DESC}}, builder.Sorts)
+		assert.Equal(t, 0, len(builder.Havings))
+		assert.Equal(t, buildsql.Combinator(""), builder.Combinator)
+		assert.Equal(t, "", builder.Search)
+		assert.Nil(t, builder.Limit)
+		assert.Equal(t, map[string]int{"p": 1}, builder.SearchTables)
+
+		where, orderBy, _, err := builder.Build("", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0", where)
+		assert.Equal(t, "ORDER BY p.name DESC", orderBy)
+	})
+
+	t.Run("should reset ParseValues too", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseValues(url.Values{"filter": {"p-name-eq-bob"}}))
+		assert.Nil(t, builder.ParseValues(url.Values{"filter": {"p-sku-eq-abc"}}))
+		assert.Equal(t, 1, len(builder.Filters))
+	})
+
+	t.Run("should keep the configuration", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().WithDialect(buildsql.Postgres)
+		builder.DefaultOperator = buildsql.Equal
+		assert.Nil(t, builder.ParseParamString("filter=p-name-bob"))
+		builder.Reset()
+
+		assert.Equal(t, 0, len(builder.Filters))
+		assert.Equal(t, buildsql.Postgres, builder.Dialect)
+		assert.Equal(t, buildsql.Equal, builder.DefaultOperator)
+	})
+}
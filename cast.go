@@ -0,0 +1,63 @@
+package buildsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// castHints are the value side casts of the type hint grammar keyed by
+// hint, e.g. eq-intarr:1,2,3 binds '{1,2,3}' cast to int[]
+var castHints = map[string]string{
+	"intarr":    "int[]",
+	"bigintarr": "bigint[]",
+	"textarr":   "text[]",
+	"uuidarr":   "uuid[]",
+}
+
+// CastDialect is implemented by dialects casting a bound value to the
+// column type, postgres among the built in dialects
+type CastDialect interface {
+	// CastValue returns the param cast to the sql type
+	CastValue(param, sqlType string) string
+}
+
+// CastValue uses CAST(... AS ...), the same as param::type, since sqlx
+// reads a :: after a named param as an escaped colon
+func (postgres) CastValue(param, sqlType string) string {
+	return fmt.Sprintf("CAST(%s AS %s)", param, sqlType)
+}
+
+// castValue renders the param cast to the type of the hint through the
+// dialect; without a dialect the postgres form is used
+func (b *QueryBuilder) castValue(param, hint string) (string, error) {
+	sqlType := castHints[hint]
+	if b.Dialect == nil {
+		return postgres{}.CastValue(param, sqlType), nil
+	}
+	dialect, ok := b.Dialect.(CastDialect)
+	if !ok {
+		return "", fmt.Errorf("the %s: cast hint requires a dialect with value casts", hint)
+	}
+	return dialect.CastValue(param, sqlType), nil
+}
+
+// castLiteral converts the comma separated value of a cast hint into
+// the array literal the cast parses, integer elements must be integers
+// and the others are quoted
+func castLiteral(hint string, raw interface{}) (string, error) {
+	elements := []string{}
+	for _, element := range strings.Split(fmt.Sprint(raw), ",") {
+		element = strings.TrimSpace(element)
+		switch hint {
+		case "intarr", "bigintarr":
+			if _, err := strconv.ParseInt(element, 10, 64); err != nil {
+				return "", fmt.Errorf("%q is not an integer", element)
+			}
+		default:
+			element = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(element) + `"`
+		}
+		elements = append(elements, element)
+	}
+	return "{" + strings.Join(elements, ",") + "}", nil
+}
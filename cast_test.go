@@ -0,0 +1,63 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderValueCasts(t *testing.T) {
+	allowed := map[string]interface{}{"a": Article{}}
+
+	t.Run("should cast the value to an int array under postgres", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+
+		where, _, namedParamMap, err := builder.Build("filter=a-tags-eq-intarr:1,2,3", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "a"."tags" = CAST(:filter_a_tags_0 AS int[])`, where)
+		assert.Equal(t, map[string]interface{}{"filter_a_tags_0": "{1,2,3}"}, namedParamMap)
+
+		where, _, args, err := builder.BuildPositional("filter=a-tags-neq-intarr:1,2", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "a"."tags" != CAST($1 AS int[])`, where)
+		assert.Equal(t, []interface{}{"{1,2}"}, args)
+	})
+
+	t.Run("should quote the elements of a text array", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build(`filter=a-tags-eq-textarr:go,"rust"`, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND a.tags = CAST(:filter_a_tags_0 AS text[])", where)
+		assert.Equal(t, `{"go","\"rust\""}`, namedParamMap["filter_a_tags_0"])
+	})
+
+	t.Run("should reject invalid casts", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		for on, msg := range map[string]string{
+			"filter=a-tags-eq-intarr:1,x":    `filter[0] a.tags: "x" is not an integer`,
+			"filter=a-tags-in-intarr:1,2":    "filter[0] a.tags: the intarr: cast hint can't be used with in",
+			"filter=a-tags-eq-floatarr:1.5":  "",
+			"filter=a-tags-anyeq-intarr:1,2": "filter[0] a.tags: the intarr: cast hint can't be used with anyeq",
+		} {
+			_, _, namedParamMap, err := builder.Build(on, allowed)
+			if msg == "" {
+				// unknown prefixes stay part of the value
+				assert.Nil(t, err, on)
+				assert.Equal(t, "floatarr:1.5", namedParamMap["filter_a_tags_0"])
+				continue
+			}
+			assert.EqualError(t, err, msg, on)
+		}
+	})
+
+	t.Run("should reject dialects without value casts", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+
+		_, _, _, err := builder.Build("filter=a-tags-eq-intarr:1,2", allowed)
+		assert.EqualError(t, err, "filter[0] a.tags: the intarr: cast hint requires a dialect with value casts")
+	})
+}
@@ -0,0 +1,121 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dialectCheck is the table CheckDialect renders its filters against
+type dialectCheck struct {
+	Text   string   `db:"text"`
+	Flag   bool     `db:"flag"`
+	Number int64    `db:"number"`
+	Tags   []string `db:"tags"`
+}
+
+// trailingOperators are the tokens a condition can't end with,
+// since they need an operand after them
+var trailingOperators = map[string]bool{
+	"=": true, "!=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true,
+	"LIKE": true, "ILIKE": true, "IN": true, "BETWEEN": true, "AND": true, "OR": true,
+	"NOT": true, "IS": true, "ANY": true, "ALL": true, "@>": true,
+}
+
+// CheckDialect renders every operator through the dialect with dummy
+// values and reports the first empty or malformed condition, such as a
+// trailing operator without an operand or unbalanced parentheses; call
+// it from a startup test for each configured dialect
+// the array and similarity operators are only checked when the dialect
+// implements ArrayDialect or TrigramDialect, operators that only group others, like or, are skipped
+func CheckDialect(d Dialect) error {
+	if d == nil {
+		return fmt.Errorf("check dialect: the dialect is nil")
+	}
+	if d.Placeholder(1) == "" {
+		return fmt.Errorf("check dialect: Placeholder returns an empty placeholder")
+	}
+	if !strings.Contains(strings.ToLower(d.QuoteIdent("ident")), "ident") {
+		return fmt.Errorf("check dialect: QuoteIdent drops the identifier")
+	}
+	for _, ci := range []bool{false, true} {
+		if strings.TrimSpace(d.LikeOperator(ci)) == "" {
+			return fmt.Errorf("check dialect: LikeOperator(%t) returns an empty operator", ci)
+		}
+	}
+
+	_, arrays := d.(ArrayDialect)
+	_, trigrams := d.(TrigramDialect)
+	allowed := map[string]interface{}{"t": dialectCheck{}}
+	for _, op := range operators {
+		if !roundTrips(op) {
+			continue
+		}
+
+		field := FilterField{TableAlias: "t", FieldName: "number", Operator: op, Value: "1"}
+		switch {
+		case op == AnyEqual || op.IsContains():
+			if !arrays {
+				continue
+			}
+			field.FieldName = "tags"
+			field.Values = []string{"a", "b"}
+		case op.IsSimilarity():
+			if !trigrams {
+				continue
+			}
+			field.FieldName = "text"
+			field.Values = []string{"a", "0.3"}
+		case op.IsMultiValue():
+			field.Values = []string{"1", "2"}
+		case op.IsLike():
+			field.FieldName = "text"
+			field.Value = "%a%"
+		case op.IsEmpty() || op == IEqual:
+			field.FieldName = "text"
+		case op.IsTruth():
+			field.FieldName = "flag"
+		}
+
+		b := &QueryBuilder{Dialect: d, Filters: []FilterField{field}}
+		where, _, _, err := b.build(allowed)
+		if err != nil {
+			return fmt.Errorf("check dialect: %s: %w", op, err)
+		}
+		if err := checkCondition(strings.TrimPrefix(where, " AND ")); err != nil {
+			return fmt.Errorf("check dialect: %s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// checkCondition reports the obvious malformations of a rendered condition
+func checkCondition(condition string) error {
+	if strings.TrimSpace(condition) == "" {
+		return fmt.Errorf("renders an empty condition")
+	}
+	if strings.Contains(condition, "  ") {
+		return fmt.Errorf("%q has an empty token", condition)
+	}
+
+	depth := 0
+	for _, r := range condition {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("%q has unbalanced parentheses", condition)
+	}
+
+	tokens := strings.Fields(condition)
+	if last := tokens[len(tokens)-1]; trailingOperators[strings.ToUpper(last)] {
+		return fmt.Errorf("%q ends with the operator %s", condition, last)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package buildsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// TimeLayouts are tried in order when coercing a value for a time column
+var TimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullInt32Type   = reflect.TypeOf(sql.NullInt32{})
+	nullInt16Type   = reflect.TypeOf(sql.NullInt16{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+	nullTimeType    = reflect.TypeOf(sql.NullTime{})
+)
+
+// typeHints are the types a filter value can be hinted as
+var typeHints = map[string]reflect.Type{
+	"int":    reflect.TypeOf(int64(0)),
+	"uint":   reflect.TypeOf(uint64(0)),
+	"float":  reflect.TypeOf(float64(0)),
+	"bool":   reflect.TypeOf(false),
+	"time":   timeType,
+	"string": reflect.TypeOf(""),
+}
+
+// isTypeHint reports whether the hint is a type hint or a value
+// side cast hint
+func isTypeHint(hint string) bool {
+	_, typed := typeHints[hint]
+	_, cast := castHints[hint]
+	return typed || cast
+}
+
+// coerceValue converts a raw filter value to the go type of the column
+// ints bind as int64, floats as float64, bools as bool and times as time.Time
+// columns of a custom type implementing sql.Scanner, e.g. a uuid type,
+// scan the value into a new instance so the driver uses its Value()
+// raw values that already implement driver.Valuer pass through untouched
+// as do values for columns of any other type
+func coerceValue(rt reflect.Type, raw interface{}) (interface{}, error) {
+	if _, ok := raw.(driver.Valuer); ok {
+		return raw, nil
+	}
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	token := fmt.Sprint(raw)
+	switch rt {
+	case timeType, nullTimeType:
+		return coerceTime(token)
+	case nullStringType:
+		return token, nil
+	case nullInt64Type, nullInt32Type, nullInt16Type:
+		return coerceInt(token)
+	case nullFloat64Type:
+		return coerceFloat(token)
+	case nullBoolType:
+		return coerceBool(token)
+	}
+
+	if scanner, ok := reflect.New(rt).Interface().(sql.Scanner); ok {
+		if err := scanner.Scan(token); err != nil {
+			return nil, fmt.Errorf("%q is not a valid %s", token, rt.Name())
+		}
+		return reflect.ValueOf(scanner).Elem().Interface(), nil
+	}
+
+	switch rt.Kind() {
+	case reflect.String:
+		return token, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return coerceInt(token)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(token, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid unsigned integer", token)
+		}
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		return coerceFloat(token)
+	case reflect.Bool:
+		return coerceBool(token)
+	}
+	return raw, nil
+}
+
+// isStringType reports whether the column holds a string
+func isStringType(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	return rt.Kind() == reflect.String || rt == nullStringType
+}
+
+// isIntType reports whether the column holds a signed or unsigned integer
+func isIntType(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt {
+	case nullInt64Type, nullInt32Type, nullInt16Type:
+		return true
+	}
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isBoolType reports whether the column holds a bool
+func isBoolType(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	return rt.Kind() == reflect.Bool || rt == nullBoolType
+}
+
+func coerceInt(token string) (interface{}, error) {
+	v, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid integer", token)
+	}
+	return v, nil
+}
+
+func coerceFloat(token string) (interface{}, error) {
+	v, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid float", token)
+	}
+	return v, nil
+}
+
+func coerceBool(token string) (interface{}, error) {
+	v, err := strconv.ParseBool(token)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid bool", token)
+	}
+	return v, nil
+}
+
+func coerceTime(token string) (interface{}, error) {
+	for _, layout := range TimeLayouts {
+		if v, err := time.Parse(layout, token); err == nil {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a valid time", token)
+}
+
+// checkBetweenOrder errors when the low bound is greater than the high
+// bound of a numeric or time column; other columns aren't compared
+func checkBetweenOrder(rt reflect.Type, low, high string) error {
+	lowValue, err := coerceValue(rt, low)
+	if err != nil {
+		return err
+	}
+	highValue, err := coerceValue(rt, high)
+	if err != nil {
+		return err
+	}
+
+	reversed := false
+	switch l := lowValue.(type) {
+	case int64:
+		reversed = l > highValue.(int64)
+	case uint64:
+		reversed = l > highValue.(uint64)
+	case float64:
+		reversed = l > highValue.(float64)
+	case time.Time:
+		reversed = l.After(highValue.(time.Time))
+	}
+
+	if reversed {
+		return fmt.Errorf("between %q and %q: the low bound is greater than the high bound", low, high)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package buildsql_test
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderCoerceValues(t *testing.T) {
+	allowed := map[string]interface{}{
+		"p":  Product{},
+		"pr": Pricing{},
+		"u":  User{},
+	}
+
+	t.Run("should bind values as the column type", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+		on := "filter=p-id-eq-7&filter=pr-amount-gte-9.5&filter=u-verified-eq-true&filter=u-created_at-btw-2024-06-12,2024-06-13&filter=p-name-like-cotton"
+
+		_, _, namedParamMap, err := builder.Build(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(7), namedParamMap["filter_p_id_0"])
+		assert.Equal(t, 9.5, namedParamMap["filter_pr_amount_0"])
+		assert.Equal(t, true, namedParamMap["filter_u_verified_0"])
+		assert.Equal(t, time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), namedParamMap["filter_u_created_at_0_0"])
+		assert.Equal(t, "%cotton%", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should keep string values when disabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, _, namedParamMap, err := builder.Build("filter=p-id-eq-7", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "7", namedParamMap["filter_p_id_0"])
+	})
+
+	t.Run("should include the filter index and field path in errors", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+		on := "filter=p-id-eq-7&filter=p-name-eq-bob&filter=pr-amount-gt-abc"
+
+		_, _, _, err := builder.Build(on, allowed)
+		assert.NotNil(t, err)
+		assert.Equal(t, `filter[2] pr.amount: "abc" is not a valid float`, err.Error())
+	})
+
+	t.Run("should include the path for multi value errors", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+
+		_, _, _, err := builder.Build("filter=p-id-in-1,x,3", allowed)
+		assert.NotNil(t, err)
+		assert.Equal(t, `filter[0] p.id: "x" is not a valid integer`, err.Error())
+	})
+
+	t.Run("should bind each in and notin element as the column type", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+
+		_, _, namedParamMap, err := builder.Build("filter=p-id-in-1,2,3&filter=pr-amount-notin-1.5,2", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"filter_p_id_0_0":      int64(1),
+			"filter_p_id_0_1":      int64(2),
+			"filter_p_id_0_2":      int64(3),
+			"filter_pr_amount_0_0": 1.5,
+			"filter_pr_amount_0_1": 2.0,
+		}, namedParamMap)
+	})
+
+	t.Run("should reject an in list mixing in a non integer", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+
+		_, _, _, err := builder.Build("filter=p-id-notin-1,2.5", allowed)
+		assert.NotNil(t, err)
+		assert.Equal(t, `filter[0] p.id: "2.5" is not a valid integer`, err.Error())
+	})
+}
+
+func TestQueryBuilderValidateBetweenOrder(t *testing.T) {
+	allowed := map[string]interface{}{
+		"pr": Pricing{},
+		"u":  User{},
+	}
+
+	t.Run("should accept in order numeric and date bounds", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ValidateBetweenOrder = true
+		_, _, _, err := builder.Build("filter=pr-amount-btw-1.5,10&filter=u-created_at-btw-2024-06-12,2024-06-13", allowed)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should reject reversed numeric bounds", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ValidateBetweenOrder = true
+		_, _, _, err := builder.Build("filter=pr-amount-btw-10,1.5", allowed)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "filter[0] pr.amount")
+	})
+
+	t.Run("should reject reversed date bounds", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.ValidateBetweenOrder = true
+		_, _, _, err := builder.Build("filter=u-created_at-btw-2024-06-13 00:00:00,2024-06-12 23:59:59", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should ignore reversed bounds when disabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, _, _, err := builder.Build("filter=pr-amount-btw-10,1.5", allowed)
+		assert.Nil(t, err)
+	})
+}
+
+// SKU is a custom column type bound through its driver.Valuer
+type SKU struct {
+	Code string
+}
+
+func (s *SKU) Scan(src interface{}) error {
+	code, ok := src.(string)
+	if !ok || !strings.HasPrefix(code, "SKU") {
+		return fmt.Errorf("invalid sku %v", src)
+	}
+	s.Code = code
+	return nil
+}
+
+func (s SKU) Value() (driver.Value, error) {
+	return strings.ToLower(s.Code), nil
+}
+
+type Variant struct {
+	ID  int64 `json:"id" db:"id"`
+	SKU SKU   `json:"sku" db:"sku"`
+}
+
+func TestQueryBuilderValuerValues(t *testing.T) {
+	t.Run("should bind a custom column type through its driver.Valuer", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+
+		where, _, namedParamMap, err := builder.Build("filter=v-sku-in-SKU1,SKU2", map[string]interface{}{
+			"v": Variant{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND v.sku IN (:filter_v_sku_0_0, :filter_v_sku_0_1)", where)
+		assert.Equal(t, SKU{Code: "SKU1"}, namedParamMap["filter_v_sku_0_0"])
+		assert.Implements(t, (*driver.Valuer)(nil), namedParamMap["filter_v_sku_0_1"])
+		assert.Equal(t, " AND v.sku IN ('sku1', 'sku2')", buildsql.Explain(where, namedParamMap))
+	})
+
+	t.Run("should report a value the custom type can't scan", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+
+		_, _, _, err := builder.Build("filter=v-sku-eq-abc", map[string]interface{}{
+			"v": Variant{},
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, `filter[0] v.sku: "abc" is not a valid SKU`, err.Error())
+	})
+
+	t.Run("should pass a driver.Valuer value through untouched", func(t *testing.T) {
+		where, namedParamMap, err := buildsql.AssembleWhere([]buildsql.FilterField{
+			{TableAlias: "v", FieldName: "sku", Operator: buildsql.Equal, Value: SKU{Code: "SKU9"}},
+		}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND v.sku = :filter_v_sku_0", where)
+		assert.Equal(t, SKU{Code: "SKU9"}, namedParamMap["filter_v_sku_0"])
+	})
+}
+
+func TestQueryBuilderTypeHints(t *testing.T) {
+	allowed := map[string]interface{}{
+		"p": Product{},
+		"u": User{},
+	}
+
+	for _, tc := range []struct {
+		on    string
+		param string
+		value interface{}
+	}{
+		{"filter=p-name-eq-int:12345", "filter_p_name_0", int64(12345)},
+		{"filter=p-name-gt-float:9.5", "filter_p_name_0", 9.5},
+		{"filter=p-name-eq-bool:true", "filter_p_name_0", true},
+		{"filter=p-name-gte-time:2024-06-12", "filter_p_name_0", time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)},
+		{"filter=p-name-in-int:1,2", "filter_p_name_0_1", int64(2)},
+	} {
+		t.Run("should coerce "+tc.on, func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+
+			_, _, namedParamMap, err := builder.Build(tc.on, allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.value, namedParamMap[tc.param])
+		})
+	}
+
+	t.Run("should coerce a function field", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.FunctionFields = map[string]string{"u.age": "age({created_at})"}
+
+		where, _, namedParamMap, err := builder.Build("filter=u-age-gt-int:18", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND age(u.created_at) > :filter_u_age_0", where)
+		assert.Equal(t, int64(18), namedParamMap["filter_u_age_0"])
+	})
+
+	t.Run("should report an invalid hinted value", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.Build("filter=p-name-eq-int:abc", allowed)
+		assert.EqualError(t, err, `filter[0] p.name: "abc" is not a valid integer`)
+	})
+
+	t.Run("should keep unknown prefixes in the value", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, namedParamMap, err := builder.Build("filter=p-name-eq-uuid:abc", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "uuid:abc", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should reject an unsupported hint on a filter field", func(t *testing.T) {
+		_, _, err := buildsql.AssembleWhere([]buildsql.FilterField{
+			{TableAlias: "p", FieldName: "name", Operator: buildsql.Equal, Value: "1", TypeHint: "decimal"},
+		}, nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should round trip the hint through Token", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("filter=p-name-eq-int:12345"))
+
+		token, err := builder.Filters[0].Token(buildsql.Delimiter)
+		assert.Nil(t, err)
+		assert.Equal(t, "p-name-eq-int%3A12345", token)
+	})
+}
@@ -0,0 +1,115 @@
+package buildsql
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// CompiledQuery is a where and order by built once from a param string
+// and reused with new values, see Compile
+// the fields, operators, sorts and the number of values of in and
+// between filters are fixed; only the values of the client filters vary
+type CompiledQuery struct {
+	Where   string
+	OrderBy string
+
+	// Params are the names of the client value params Bind expects
+	Params []string
+
+	params    map[string]compiledParam
+	fixed     map[string]interface{}
+	boolValue func(interface{}) interface{}
+}
+
+// compiledParam is a client value param of a compiled query with the
+// operator and column type Bind converts its values with like Build
+type compiledParam struct {
+	operator   Operator
+	coerceType reflect.Type
+}
+
+// Compile builds the param string once into a CompiledQuery, so a hot
+// path with a fixed query shape skips the parsing, reflection and
+// rendering and only binds its values per request
+// the values in the param string are placeholders, mandatory, preset
+// and context bound values are fixed at compile time
+// example:
+//
+//	compiled, err := builder.Compile("filter=p-id-in-0,0&filter=p-name-like-x&sortOn=p-id", allowed)
+//	// compiled.Params: filter_p_id_0_0, filter_p_id_0_1, filter_p_name_0
+//	namedParamMap, err := compiled.Bind(map[string]interface{}{
+//		"filter_p_id_0_0": 1, "filter_p_id_0_1": 2, "filter_p_name_0": "bob",
+//	})
+func (b *QueryBuilder) Compile(paramString string, allowed map[string]interface{}) (*CompiledQuery, error) {
+	req, err := b.parse(paramString)
+	if err != nil {
+		return nil, err
+	}
+	req.variable = make(map[string]compiledParam)
+
+	where, orderBy, namedParamMap, err := req.build(allowed)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err = req.paginate(orderBy, namedParamMap, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &CompiledQuery{
+		Where:     where,
+		OrderBy:   orderBy,
+		params:    req.variable,
+		fixed:     make(map[string]interface{}),
+		boolValue: req.boolValue,
+	}
+	for name, value := range namedParamMap {
+		if _, ok := req.variable[name]; ok {
+			compiled.Params = append(compiled.Params, name)
+			continue
+		}
+		compiled.fixed[name] = value
+	}
+	sort.Strings(compiled.Params)
+	return compiled, nil
+}
+
+// Bind returns the named params of the compiled query with the values
+// keyed by param name, every one of Params is required and other names
+// are rejected, so a value can't replace a fixed mandatory value
+// like values are wrapped in their % wildcards like the parser does,
+// others are converted like Build converts them: to the column type
+// with CoerceValues or a type hint, and to a bool for InlineBooleans,
+// which are bound instead of inlined so they can vary
+func (q *CompiledQuery) Bind(values map[string]interface{}) (map[string]interface{}, error) {
+	for name := range values {
+		if _, ok := q.params[name]; !ok {
+			return nil, fmt.Errorf("bind: %s is not a param of the compiled query", name)
+		}
+	}
+
+	namedParamMap := make(map[string]interface{}, len(q.fixed)+len(q.Params))
+	for name, value := range q.fixed {
+		namedParamMap[name] = value
+	}
+	for _, name := range q.Params {
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("bind: %s requires a value", name)
+		}
+		param := q.params[name]
+		if param.operator.IsLike() {
+			value = param.operator.pattern(value)
+		}
+		if param.coerceType != nil {
+			coerced, err := coerceValue(param.coerceType, value)
+			if err != nil {
+				return nil, fmt.Errorf("bind: %s: %w", name, err)
+			}
+			value = coerced
+		}
+		namedParamMap[name] = q.boolValue(value)
+	}
+	return namedParamMap, nil
+}
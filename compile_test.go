@@ -0,0 +1,124 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderCompile(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	spec := "filter=p-id-in-0,0&filter=p-name-like-x&sortOn=-p-id&limit=10"
+
+	t.Run("should build the same query as Build", func(t *testing.T) {
+		compiled, err := buildsql.NewQueryBuilder().Compile(spec, allowed)
+		assert.Nil(t, err)
+
+		where, orderBy, _, err := buildsql.NewQueryBuilder().Build(spec, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, where, compiled.Where)
+		assert.Equal(t, orderBy, compiled.OrderBy)
+		assert.Equal(t, []string{"filter_p_id_0_0", "filter_p_id_0_1", "filter_p_name_0"}, compiled.Params)
+	})
+
+	t.Run("should bind new values twice without leaking between binds", func(t *testing.T) {
+		compiled, err := buildsql.NewQueryBuilder().Compile(spec, allowed)
+		assert.Nil(t, err)
+
+		first, err := compiled.Bind(map[string]interface{}{"filter_p_id_0_0": 1, "filter_p_id_0_1": 2, "filter_p_name_0": "bob"})
+		assert.Nil(t, err)
+		second, err := compiled.Bind(map[string]interface{}{"filter_p_id_0_0": 3, "filter_p_id_0_1": 4, "filter_p_name_0": "ann"})
+		assert.Nil(t, err)
+
+		assert.Equal(t, map[string]interface{}{"filter_p_id_0_0": 1, "filter_p_id_0_1": 2, "filter_p_name_0": "%bob%"}, first)
+		assert.Equal(t, map[string]interface{}{"filter_p_id_0_0": 3, "filter_p_id_0_1": 4, "filter_p_name_0": "%ann%"}, second)
+	})
+
+	t.Run("should keep the mandatory and context values fixed", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "t", FieldName: "tenant_id", Operator: buildsql.Equal, Value: 7})
+		builder.BindContextValue("p", "sku", "own")
+		compiled, err := builder.Compile("filter=p-name-eq-x&filter=p-sku-eq-x", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"filter_p_name_0"}, compiled.Params)
+
+		namedParamMap, err := compiled.Bind(map[string]interface{}{"filter_p_name_0": "gloves"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"mandatory_t_tenant_id_0": 7, "filter_p_name_0": "gloves", "filter_p_sku_0": "own"}, namedParamMap)
+
+		_, err = compiled.Bind(map[string]interface{}{"filter_p_name_0": "gloves", "mandatory_t_tenant_id_0": 8})
+		assert.EqualError(t, err, "bind: mandatory_t_tenant_id_0 is not a param of the compiled query")
+	})
+
+	t.Run("should require every param", func(t *testing.T) {
+		compiled, err := buildsql.NewQueryBuilder().Compile(spec, allowed)
+		assert.Nil(t, err)
+
+		_, err = compiled.Bind(map[string]interface{}{"filter_p_id_0_0": 1, "filter_p_id_0_1": 2})
+		assert.EqualError(t, err, "bind: filter_p_name_0 requires a value")
+	})
+
+	t.Run("should coerce the bound values like Build", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.CoerceValues = true
+		users := map[string]interface{}{"u": User{}}
+		compiled, err := builder.Compile("filter=u-id-eq-0", users)
+		assert.Nil(t, err)
+
+		namedParamMap, err := compiled.Bind(map[string]interface{}{"filter_u_id_0": "42"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"filter_u_id_0": int64(42)}, namedParamMap)
+
+		_, err = compiled.Bind(map[string]interface{}{"filter_u_id_0": "abc"})
+		assert.NotNil(t, err)
+		_, _, _, err = builder.Build("filter=u-id-eq-abc", users)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should bind inlined booleans so they can vary", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.InlineBooleans = true
+		compiled, err := builder.Compile("filter=u-require_reset-eq-true", map[string]interface{}{"u": User{}})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.require_reset = :filter_u_require_reset_0", compiled.Where)
+		assert.Equal(t, []string{"filter_u_require_reset_0"}, compiled.Params)
+
+		namedParamMap, err := compiled.Bind(map[string]interface{}{"filter_u_require_reset_0": "false"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"filter_u_require_reset_0": false}, namedParamMap)
+	})
+
+	t.Run("should return the parse errors", func(t *testing.T) {
+		_, err := buildsql.NewQueryBuilder().Compile("filter=p-id-nope-1", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func BenchmarkCompiledQueryBind(b *testing.B) {
+	allowed := map[string]interface{}{"p": Product{}}
+	spec := "filter=p-id-in-0,0&filter=p-name-like-x&sortOn=-p-id"
+	values := map[string]interface{}{"filter_p_id_0_0": 1, "filter_p_id_0_1": 2, "filter_p_name_0": "bob"}
+
+	b.Run("build", func(b *testing.B) {
+		builder := buildsql.NewQueryBuilder()
+		for i := 0; i < b.N; i++ {
+			if _, _, _, err := builder.Build("filter=p-id-in-1,2&filter=p-name-like-bob&sortOn=-p-id", allowed); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("bind", func(b *testing.B) {
+		compiled, err := buildsql.NewQueryBuilder().Compile(spec, allowed)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := compiled.Bind(values); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package buildsql_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderConcurrentBuild(t *testing.T) {
+	t.Run("should build from many goroutines on a shared builder", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+		builder.CoerceValues = true
+		builder.DefaultSort = []buildsql.SortField{{TableAlias: "p", FieldName: "id", Direction: buildsql.DESC}}
+		builder.AppendDefaultSort = true
+		builder.AddMandatoryFilter(buildsql.FilterField{TableAlias: "p", FieldName: "slug", Operator: buildsql.NotEqual, Value: "archived"})
+		allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				on := fmt.Sprintf("filter=p-id-eq-%d&filter=pr-amount-btw-1,%d&sortOn=-p-name", i, i+10)
+
+				where, orderBy, namedParamMap, err := builder.Build(on, allowed)
+				assert.Nil(t, err)
+				assert.Equal(t, ` AND "p"."slug" != :mandatory_p_slug_0 AND "p"."id" = :filter_p_id_0 AND "pr"."amount" BETWEEN :filter_pr_amount_0_0 AND :filter_pr_amount_0_1`, where)
+				assert.Equal(t, `ORDER BY "p"."name" DESC, "p"."id" DESC`, orderBy)
+				assert.Equal(t, int64(i), namedParamMap["filter_p_id_0"])
+
+				query, _, err := builder.BuildSelect("product p JOIN pricing pr ON pr.product_id = p.id", []string{"p.id"}, on, allowed)
+				assert.Nil(t, err)
+				assert.NotEmpty(t, query)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, 0, len(builder.Filters))
+		assert.Equal(t, 0, len(builder.Sorts))
+	})
+
+	t.Run("should take the query shape from many goroutines", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("filter=p-sku-eq-gloves&sortOn=p-id"))
+		allowed := map[string]interface{}{"p": Product{}}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				shape, err := builder.QueryShape(allowed)
+				assert.Nil(t, err)
+				assert.Equal(t, "p.sku = :filter_p_sku_0 ORDER BY p.id ASC", shape)
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("should build on top of a parsed saved view from many goroutines", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("filter=p-sku-eq-gloves"))
+		allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				where, _, _, err := builder.Build(fmt.Sprintf("filter=pr-amount-gt-%d", i), allowed)
+				assert.Nil(t, err)
+				assert.Equal(t, " AND p.sku = :filter_p_sku_0 AND pr.amount > :filter_pr_amount_0", where)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, map[string]int{"p": 1}, builder.SearchTables)
+	})
+}
@@ -0,0 +1,50 @@
+package buildsql
+
+import "fmt"
+
+// BuildDelete builds a single table delete statement
+// the columns are rendered without their alias, since the table
+// isn't aliased, so allowed must hold the table's alias alone; it
+// refuses to build without a condition to avoid accidental full table
+// deletes unless AllowUnfilteredDelete is set. RequireFilter isn't
+// reused for the guard, its zero value leaves Build unguarded and a
+// delete must be guarded by default
+// example:
+//
+//	query, namedParamMap, err := builder.BuildDelete("product", "filter=p-id-eq-1", allowed)
+//	// DELETE FROM product WHERE id = :filter_p_id_0
+func (b *QueryBuilder) BuildDelete(table string, paramString string, allowed map[string]interface{}) (query string, namedParamMap map[string]interface{}, err error) {
+	if table == "" {
+		return "", nil, fmt.Errorf("delete: table is required")
+	}
+
+	req, err := b.parse(paramString)
+	if err != nil {
+		return "", nil, err
+	}
+	req.unqualified = true
+
+	// a filter on another alias would be applied to the table's own column
+	allowed = req.allowedTables(allowed)
+	if len(allowed) != 1 {
+		return "", nil, fmt.Errorf("delete: allowed must hold only the alias of %s, got %d aliases", table, len(allowed))
+	}
+
+	where, _, namedParamMap, err := req.build(allowed)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s", table)
+	if comment := b.comment(); comment != "" {
+		query = comment + " " + query
+	}
+
+	if where == "" {
+		if !b.AllowUnfilteredDelete {
+			return "", nil, fmt.Errorf("delete: at least one filter is required")
+		}
+		return query, namedParamMap, nil
+	}
+	return fmt.Sprintf("%s WHERE %s", query, where[len(" AND "):]), namedParamMap, nil
+}
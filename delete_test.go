@@ -0,0 +1,66 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderDelete(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should build a delete with unqualified columns", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		query, namedParamMap, err := builder.BuildDelete("product", "filter=p-id-in-1,2&filter=p-name-eq-gloves", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "DELETE FROM product WHERE id IN (:filter_p_id_0_0, :filter_p_id_0_1) AND name = :filter_p_name_0", query)
+		assert.Equal(t, "gloves", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should quote the columns with a dialect", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+
+		query, _, err := builder.BuildDelete("product", "filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, `DELETE FROM product WHERE "id" = :filter_p_id_0`, query)
+	})
+
+	t.Run("should refuse to delete without a filter", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, err := builder.BuildDelete("product", "", allowed)
+		assert.NotNil(t, err)
+
+		_, _, err = builder.BuildDelete("product", "filter=x-id-eq-1", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should refuse filters on another table", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, err := builder.BuildDelete("product", "filter=pr-amount-eq-1", map[string]interface{}{"p": Product{}, "pr": Pricing{}})
+		assert.EqualError(t, err, "delete: allowed must hold only the alias of product, got 2 aliases")
+	})
+
+	t.Run("should delete every row when allowed", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowUnfilteredDelete = true
+
+		query, _, err := builder.BuildDelete("product", "", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "DELETE FROM product", query)
+	})
+
+	t.Run("should qualify the columns again after a delete", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, _, err := builder.BuildDelete("product", "filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+
+		where, _, _, err := builder.Build("filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id = :filter_p_id_0", where)
+	})
+}
@@ -0,0 +1,250 @@
+package buildsql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Dialect controls how identifiers and placeholders are rendered
+// for a specific database
+type Dialect interface {
+	// Placeholder returns the placeholder for the n-th bound param, starting at 1
+	Placeholder(n int) string
+	// QuoteIdent quotes a table alias or column name
+	QuoteIdent(s string) string
+	// LikeOperator returns the pattern match operator, ci is true for
+	// the case insensitive ilike operators
+	LikeOperator(ci bool) string
+}
+
+type postgres struct{}
+
+func (postgres) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (postgres) QuoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func (postgres) LikeOperator(ci bool) string {
+	if ci {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+// Postgres renders $1, $2 placeholders and "double quoted" identifiers
+var Postgres Dialect = postgres{}
+
+type mysql struct{}
+
+func (mysql) Placeholder(n int) string {
+	return "?"
+}
+
+func (mysql) QuoteIdent(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
+
+// LikeOperator is LIKE either way, mysql's default collations
+// already compare case insensitively
+func (mysql) LikeOperator(ci bool) string {
+	return "LIKE"
+}
+
+// MySQL renders ? placeholders and `backtick` quoted identifiers
+var MySQL Dialect = mysql{}
+
+type sqlite struct{}
+
+func (sqlite) Placeholder(n int) string {
+	return "?"
+}
+
+func (sqlite) QuoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// LikeOperator is LIKE either way, sqlite's LIKE is already
+// case insensitive for ascii
+func (sqlite) LikeOperator(ci bool) string {
+	return "LIKE"
+}
+
+// SQLite renders ? placeholders and "double quoted" identifiers
+var SQLite Dialect = sqlite{}
+
+type sqlServer struct{}
+
+func (sqlServer) Placeholder(n int) string {
+	return fmt.Sprintf("@p%d", n)
+}
+
+func (sqlServer) QuoteIdent(s string) string {
+	return "[" + strings.ReplaceAll(s, "]", "]]") + "]"
+}
+
+// LikeOperator is LIKE either way, case sensitivity follows the
+// column collation
+func (sqlServer) LikeOperator(ci bool) string {
+	return "LIKE"
+}
+
+// SQLServer renders @p1, @p2 placeholders and [bracket] quoted identifiers
+// for github.com/microsoft/go-mssqldb
+var SQLServer Dialect = sqlServer{}
+
+// column renders alias.field, quoted when a dialect is set
+// the alias is left out for single table statements
+func (b *QueryBuilder) column(tableAlias, fieldName string) string {
+	fieldName = b.viewColumn(tableAlias, fieldName)
+	if tableAlias == "" || b.unqualified {
+		if b.Dialect == nil {
+			return fieldName
+		}
+		return b.Dialect.QuoteIdent(fieldName)
+	}
+	if b.Dialect == nil {
+		return fmt.Sprintf("%s.%s", tableAlias, fieldName)
+	}
+	return fmt.Sprintf("%s.%s", b.Dialect.QuoteIdent(tableAlias), b.Dialect.QuoteIdent(fieldName))
+}
+
+// operator renders the sql operator, asking the dialect for the
+// pattern match operators
+func (b *QueryBuilder) operator(op Operator) string {
+	if b.Dialect == nil || !op.IsLike() {
+		return op.Convert()
+	}
+
+	ci := op == ILike || op == OrILike || op == NotILike
+	like := b.Dialect.LikeOperator(ci)
+	if op == NotLike || op == NotILike {
+		return "NOT " + like
+	}
+	return like
+}
+
+// bindPositional replaces the named params in the queries with the
+// dialect placeholders, numbered in the order they appear across the
+// queries, and returns the values in that same order
+func bindPositional(dialect Dialect, namedParamMap map[string]interface{}, queries ...string) ([]string, []interface{}) {
+	var values []interface{}
+	for i, query := range queries {
+		queries[i] = namedParamPattern.ReplaceAllStringFunc(query, func(match string) string {
+			value, ok := namedParamMap[match[1:]]
+			if !ok {
+				return match
+			}
+			values = append(values, value)
+			return dialect.Placeholder(len(values))
+		})
+	}
+	return queries, values
+}
+
+// PostgresMaxParams is the most bind parameters a postgres statement takes
+const PostgresMaxParams = 65535
+
+// ParamCount returns how many parameters the driver binds for the queries,
+// every occurrence of a named param counts once, as the positional and
+// sqlx bindings repeat the value per occurrence; without queries it's the
+// size of the param map, one per value of the multi value operators
+// example:
+//
+//	where, orderBy, namedParamMap, err := builder.Seek(on, after)
+//	if buildsql.ParamCount(namedParamMap, where, orderBy) > buildsql.PostgresMaxParams {
+//		// too many values, bind the in lists as arrays instead
+//	}
+func ParamCount(namedParamMap map[string]interface{}, queries ...string) int {
+	if len(queries) == 0 {
+		return len(namedParamMap)
+	}
+
+	count := 0
+	for _, query := range queries {
+		for _, match := range namedParamPattern.FindAllString(query, -1) {
+			if _, ok := namedParamMap[match[1:]]; ok {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// SetPlaceholderStyle sets the dialect whose placeholders BuildPositional
+// emits, e.g. Postgres for $1, $2 with database/sql and lib/pq, without
+// changing the identifier quoting of the Dialect
+func (b *QueryBuilder) SetPlaceholderStyle(dialect Dialect) *QueryBuilder {
+	b.PlaceholderStyle = dialect
+	return b
+}
+
+// BuildPositional builds like Build but emits the positional placeholders
+// of the PlaceholderStyle, or of the Dialect when it's unset, and returns
+// the args in placeholder order; in and between lists bind one arg per
+// value, numbered left to right across the where and then the order by
+// example:
+//
+//	builder.SetPlaceholderStyle(buildsql.Postgres)
+//	where, orderBy, args, err := builder.BuildPositional("filter=p-id-in-1,2", allowed)
+//	// AND p.id IN ($1, $2)
+//	rows, err := db.Query("SELECT * FROM product p WHERE 1 = 1"+where+" "+orderBy, args...)
+func (b *QueryBuilder) BuildPositional(paramString string, allowed map[string]interface{}, paginator ...*Paginator) (where string, orderBy string, args []interface{}, err error) {
+	style := b.PlaceholderStyle
+	if style == nil {
+		style = b.Dialect
+	}
+	if style == nil {
+		return "", "", nil, fmt.Errorf("BuildPositional requires a PlaceholderStyle or Dialect")
+	}
+
+	where, orderBy, namedParamMap, err := b.Build(paramString, allowed, paginator...)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	queries, args := bindPositional(style, namedParamMap, where, orderBy)
+	return queries[0], queries[1], args, nil
+}
+
+// BuildNamedArgs builds like Build but emits @p1, @p2 placeholders and
+// returns the values as sql.NamedArg named p1, p2, ready for go-mssqldb;
+// the placeholders are @pN whatever the Dialect, which only quotes
+// example:
+//
+//	builder.Dialect = buildsql.SQLServer
+//	where, orderBy, args, err := builder.BuildNamedArgs(on, allowed)
+func (b *QueryBuilder) BuildNamedArgs(paramString string, allowed map[string]interface{}) (where string, orderBy string, args []sql.NamedArg, err error) {
+	where, orderBy, namedParamMap, err := b.Build(paramString, allowed)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	queries, values := bindPositional(SQLServer, namedParamMap, where, orderBy)
+	where, orderBy = queries[0], queries[1]
+	for i, value := range values {
+		args = append(args, sql.Named(fmt.Sprintf("p%d", i+1), value))
+	}
+	return where, orderBy, args, nil
+}
+
+// BuildGorm builds the where as a ? placeholder condition with its args
+// in placeholder order, ready for *gorm.DB.Where; identifiers are quoted
+// by the Dialect when one is set
+// example:
+//
+//	cond, args, err := builder.BuildGorm(on, allowed)
+//	db.Where(cond, args...).Find(&products)
+func (b *QueryBuilder) BuildGorm(paramString string, allowed map[string]interface{}) (cond string, args []interface{}, err error) {
+	where, _, namedParamMap, err := b.Build(paramString, allowed)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// only the ? placeholders of the mysql dialect are used
+	queries, args := bindPositional(MySQL, namedParamMap, strings.TrimPrefix(where, " AND "))
+	return queries[0], args, nil
+}
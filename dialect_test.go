@@ -0,0 +1,408 @@
+package buildsql_test
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLServerDialect(t *testing.T) {
+	t.Run("should emit @pN placeholders and bracket quoted identifiers", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.SQLServer
+		on := "filter=p-id-in-1,2,3&sortOn=-p-name"
+
+		where, orderBy, args, err := builder.BuildNamedArgs(on, map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND [p].[id] IN (@p1, @p2, @p3)", where)
+		assert.Equal(t, "ORDER BY [p].[name] DESC", orderBy)
+		assert.Equal(t, []sql.NamedArg{
+			sql.Named("p1", "1"),
+			sql.Named("p2", "2"),
+			sql.Named("p3", "3"),
+		}, args)
+	})
+
+	t.Run("should number placeholders across multi value operators", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.SQLServer
+		on := "filter=pr-amount-btw-10,20"
+
+		where, _, args, err := builder.BuildNamedArgs(on, map[string]interface{}{
+			"pr": Pricing{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND [pr].[amount] BETWEEN @p1 AND @p2", where)
+		assert.Equal(t, 2, len(args))
+		assert.Equal(t, "p2", args[1].Name)
+		assert.Equal(t, "20", args[1].Value)
+	})
+
+	t.Run("should emit @pN named args whatever the dialect", func(t *testing.T) {
+		for _, dialect := range []buildsql.Dialect{nil, buildsql.Postgres, buildsql.MySQL, &oracle{calls: map[string]int{}}} {
+			builder := buildsql.NewQueryBuilder()
+			builder.Dialect = dialect
+			where, _, args, err := builder.BuildNamedArgs("filter=p-id-in-1,2", map[string]interface{}{
+				"p": Product{},
+			})
+			assert.Nil(t, err)
+			assert.True(t, strings.HasSuffix(where, " IN (@p1, @p2)"), where)
+			assert.Equal(t, []sql.NamedArg{sql.Named("p1", "1"), sql.Named("p2", "2")}, args)
+		}
+	})
+}
+
+// oracle is a custom dialect recording the hooks the builder calls
+type oracle struct {
+	calls map[string]int
+}
+
+func (o *oracle) Placeholder(n int) string {
+	o.calls["Placeholder"]++
+	return fmt.Sprintf(":p%d", n)
+}
+
+func (o *oracle) QuoteIdent(s string) string {
+	o.calls["QuoteIdent"]++
+	return `"` + strings.ToUpper(s) + `"`
+}
+
+func (o *oracle) LikeOperator(ci bool) string {
+	o.calls["LikeOperator"]++
+	if ci {
+		return "LIKE_CI"
+	}
+	return "LIKE"
+}
+
+func TestCustomDialect(t *testing.T) {
+	t.Run("should render through the dialect hooks", func(t *testing.T) {
+		dialect := &oracle{calls: map[string]int{}}
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = dialect
+
+		where, orderBy, args, err := builder.BuildPositional("filter=p-name-ilike-cotton&filter=p-id-eq-1&sortOn=p-id", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "P"."NAME" LIKE_CI :p1 AND "P"."ID" = :p2`, where)
+		assert.Equal(t, `ORDER BY "P"."ID" ASC`, orderBy)
+		assert.Equal(t, 2, len(args))
+		assert.Equal(t, "%cotton%", args[0])
+		assert.Equal(t, 1, dialect.calls["LikeOperator"])
+		assert.True(t, dialect.calls["QuoteIdent"] > 0)
+		assert.True(t, dialect.calls["Placeholder"] > 0)
+	})
+
+	t.Run("should negate the dialect like operator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = &oracle{calls: map[string]int{}}
+
+		where, _, _, err := builder.Build("filter=p-name-nilike-cotton", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "P"."NAME" NOT LIKE_CI :filter_p_name_0`, where)
+	})
+}
+
+func TestBuiltinDialects(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	for _, tc := range []struct {
+		name    string
+		dialect buildsql.Dialect
+		where   string
+	}{
+		{"postgres", buildsql.Postgres, ` AND "p"."name" ILIKE :filter_p_name_0`},
+		{"mysql", buildsql.MySQL, " AND `p`.`name` LIKE :filter_p_name_0"},
+		{"sqlite", buildsql.SQLite, ` AND "p"."name" LIKE :filter_p_name_0`},
+		{"sqlserver", buildsql.SQLServer, " AND [p].[name] LIKE :filter_p_name_0"},
+	} {
+		t.Run("should render ilike for "+tc.name, func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+			builder.Dialect = tc.dialect
+
+			where, _, _, err := builder.Build("filter=p-name-ilike-cotton", allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.where, where)
+		})
+	}
+}
+
+func TestQueryBuilderGorm(t *testing.T) {
+	t.Run("should order the args by the ? positions", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		on := "filter=p-name-like-cotton&filter=pr-amount-btw-10,20&filter=p-id-in-3,1,2&filter=p-sku-eq-g1"
+
+		cond, args, err := builder.BuildGorm(on, map[string]interface{}{
+			"p":  Product{},
+			"pr": Pricing{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "p.name LIKE ? AND pr.amount BETWEEN ? AND ? AND p.id IN (?, ?, ?) AND p.sku = ?", cond)
+		assert.Equal(t, []interface{}{"%cotton%", "10", "20", "3", "1", "2", "g1"}, args)
+	})
+
+	t.Run("should return an empty condition without filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		cond, args, err := builder.BuildGorm("sortOn=p-id", map[string]interface{}{"p": Product{}})
+		assert.Nil(t, err)
+		assert.Equal(t, "", cond)
+		assert.Nil(t, args)
+	})
+}
+
+func TestQueryBuilderRandomSort(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	for _, tc := range []struct {
+		name    string
+		dialect buildsql.Dialect
+		orderBy string
+	}{
+		{"no dialect", nil, "ORDER BY RANDOM()"},
+		{"postgres", buildsql.Postgres, "ORDER BY RANDOM()"},
+		{"sqlite", buildsql.SQLite, "ORDER BY RANDOM()"},
+		{"mysql", buildsql.MySQL, "ORDER BY RAND()"},
+		{"sqlserver", buildsql.SQLServer, "ORDER BY NEWID()"},
+	} {
+		t.Run("should order randomly for "+tc.name, func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+			builder.Dialect = tc.dialect
+			builder.AllowRandomSort = true
+
+			_, orderBy, _, err := builder.Build("sortOn=@random", allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.orderBy, orderBy)
+		})
+	}
+
+	t.Run("should reject random ordering unless allowed", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.Build("sortOn=@random", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a dialect without random ordering", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = &oracle{calls: map[string]int{}}
+		builder.AllowRandomSort = true
+
+		_, _, _, err := builder.Build("sortOn=@random", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should keep the client sort order around the random ordering", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowRandomSort = true
+		builder.StrictAliases = true
+
+		_, orderBy, _, err := builder.Build("sortOn=-p-amount&sortOn=@random", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.amount DESC, RANDOM()", orderBy)
+	})
+}
+
+func TestParamCount(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+	t.Run("should count every value of the multi value operators", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, namedParamMap, err := builder.Build("filter=p-id-in-1,2,3&filter=pr-amount-btw-10,20&filter=p-name-eq-gloves&filter=p-sku-isnull", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, 6, len(namedParamMap))
+		assert.Equal(t, 6, buildsql.ParamCount(namedParamMap))
+		assert.Equal(t, 6, buildsql.ParamCount(namedParamMap, where, orderBy))
+	})
+
+	t.Run("should count each occurrence of a repeated param", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterTable("p", Product{}, "id"))
+
+		where, orderBy, namedParamMap, err := builder.Seek("sortOn=p-name", map[string]interface{}{
+			"p.name": "cotton gloves",
+			"p.id":   42,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, buildsql.ParamCount(namedParamMap))
+		assert.Equal(t, 3, buildsql.ParamCount(namedParamMap, where, orderBy))
+	})
+}
+
+func TestCheckDialect(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		dialect buildsql.Dialect
+	}{
+		{"postgres", buildsql.Postgres},
+		{"mysql", buildsql.MySQL},
+		{"sqlite", buildsql.SQLite},
+		{"sqlserver", buildsql.SQLServer},
+		{"custom", &oracle{calls: map[string]int{}}},
+	} {
+		t.Run("should pass for "+tc.name, func(t *testing.T) {
+			assert.Nil(t, buildsql.CheckDialect(tc.dialect))
+		})
+	}
+
+	t.Run("should report a dialect rendering an empty like operator", func(t *testing.T) {
+		err := buildsql.CheckDialect(blankLike{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should report a trailing operator without an operand", func(t *testing.T) {
+		err := buildsql.CheckDialect(danglingArray{})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "ends with the operator @>")
+	})
+}
+
+// blankLike is a broken dialect without a LIKE operator
+type blankLike struct{}
+
+func (blankLike) Placeholder(n int) string   { return "?" }
+func (blankLike) QuoteIdent(s string) string { return s }
+func (blankLike) LikeOperator(ci bool) string {
+	return ""
+}
+
+// danglingArray is a broken array dialect dropping the contains operand
+type danglingArray struct{ blankLike }
+
+func (danglingArray) LikeOperator(ci bool) string { return "LIKE" }
+func (danglingArray) ArrayContains(column, param string) string {
+	return param + " = ANY(" + column + ")"
+}
+func (danglingArray) ArrayIn(column, param string, negate bool) string {
+	return column + " = ANY(" + param + ")"
+}
+func (danglingArray) ArrayContainsAll(column string, params []string, negate bool) string {
+	return column + " @>"
+}
+
+// sqlizer is the squirrel.Sqlizer interface
+type sqlizer interface {
+	ToSql() (string, []interface{}, error)
+}
+
+func TestQueryBuilderToSqlizer(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+	t.Run("should return ? placeholders and args in order", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		cond, err := builder.ToSqlizer("filter=pr-amount-btw-10,20&filter=p-id-in-3,1&filter=p-name-like-cotton", allowed)
+		assert.Nil(t, err)
+
+		var s sqlizer = cond
+		query, args, err := s.ToSql()
+		assert.Nil(t, err)
+		assert.Equal(t, "pr.amount BETWEEN ? AND ? AND p.id IN (?, ?) AND p.name LIKE ?", query)
+		assert.Equal(t, []interface{}{"10", "20", "3", "1", "%cotton%"}, args)
+	})
+
+	t.Run("should render an always true condition without filters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		cond, err := builder.ToSqlizer("sortOn=p-id", allowed)
+		assert.Nil(t, err)
+		query, args, err := cond.ToSql()
+		assert.Nil(t, err)
+		assert.Equal(t, "(1=1)", query)
+		assert.Nil(t, args)
+	})
+
+	t.Run("should return the build error", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, err := builder.ToSqlizer("filter=p-id-bogus-1", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderPositional(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+
+	t.Run("should number $N placeholders in where order", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().SetPlaceholderStyle(buildsql.Postgres)
+		on := "filter=p-name-like-cotton&filter=pr-amount-btw-10,20&filter=p-id-in-3,1,2&filter=p-sku-eq-g1&sortOn=-p-id"
+
+		where, orderBy, args, err := builder.BuildPositional(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name LIKE $1 AND pr.amount BETWEEN $2 AND $3 AND p.id IN ($4, $5, $6) AND p.sku = $7", where)
+		assert.Equal(t, "ORDER BY p.id DESC", orderBy)
+		assert.Equal(t, []interface{}{"%cotton%", "10", "20", "3", "1", "2", "g1"}, args)
+	})
+
+	t.Run("should continue the numbering into a bound paginator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+
+		where, orderBy, args, err := builder.BuildPositional("filter=p-id-eq-1&sortOn=p-id", allowed, &buildsql.Paginator{Limit: 10, Offset: 20, Bind: true})
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "p"."id" = $1`, where)
+		assert.Equal(t, `ORDER BY "p"."id" ASC LIMIT $2 OFFSET $3`, orderBy)
+		assert.Equal(t, []interface{}{"1", 10, 20}, args)
+	})
+
+	t.Run("should keep named params in Build", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().SetPlaceholderStyle(buildsql.Postgres)
+
+		where, _, _, err := builder.Build("filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id = :filter_p_id_0", where)
+	})
+
+	t.Run("should require a placeholder style", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.BuildPositional("filter=p-id-eq-1", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderMySQLPlaceholders(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+	on := "filter=p-id-in-3,1,2&filter=pr-amount-btw-10,20&filter=p-name-eq-gloves&filter=p-sku-notin-a,b"
+
+	t.Run("should emit one ? per value in where order", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().SetPlaceholderStyle(buildsql.MySQL)
+
+		where, _, args, err := builder.BuildPositional(on, allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id IN (?, ?, ?) AND pr.amount BETWEEN ? AND ? AND p.name = ? AND p.sku NOT IN (?, ?)", where)
+		assert.Equal(t, []interface{}{"3", "1", "2", "10", "20", "gloves", "a", "b"}, args)
+	})
+
+	t.Run("should quote identifiers with the MySQL dialect", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+
+		where, _, args, err := builder.BuildPositional("filter=p-id-in-1,2", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND `p`.`id` IN (?, ?)", where)
+		assert.Equal(t, []interface{}{"1", "2"}, args)
+	})
+
+	t.Run("should return the args in the same order every build", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().SetPlaceholderStyle(buildsql.MySQL)
+
+		_, _, first, err := builder.BuildPositional(on, allowed)
+		assert.Nil(t, err)
+		for i := 0; i < 50; i++ {
+			_, _, args, err := builder.BuildPositional(on, allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, first, args)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DistinctDialect is implemented by dialects supporting DISTINCT ON
+type DistinctDialect interface {
+	// DistinctOn returns the select modifier keeping the first row
+	// of each group of the columns
+	DistinctOn(columns []string) string
+}
+
+func (postgres) DistinctOn(columns []string) string {
+	return "DISTINCT ON (" + strings.Join(columns, ", ") + ")"
+}
+
+// distinctOn validates the DistinctOn alias.field list, renders the
+// select modifier through the dialect and moves the distinct columns
+// to the front of the sorts, as DISTINCT ON requires
+// without a dialect the postgres form is used
+func (b *QueryBuilder) distinctOn(allowed map[string]interface{}) (string, error) {
+	if len(b.DistinctOn) == 0 {
+		return "", nil
+	}
+	b.allowed = b.allowedTables(allowed)
+
+	var dialect DistinctDialect = postgres{}
+	if b.Dialect != nil {
+		var ok bool
+		if dialect, ok = b.Dialect.(DistinctDialect); !ok {
+			return "", fmt.Errorf("distinct on: the dialect doesn't support DISTINCT ON")
+		}
+	}
+
+	columns := []string{}
+	leading := []SortField{}
+	for _, distinct := range b.DistinctOn {
+		tableAlias, fieldName, ok := strings.Cut(distinct, ".")
+		if !ok {
+			return "", fmt.Errorf("distinct on: %s is not an alias.field", distinct)
+		}
+		structField, ok := b.lookupField(allowed, tableAlias, fieldName)
+		if !ok {
+			return "", fmt.Errorf("distinct on: %s is not an allowed field", distinct)
+		}
+		// the distinct columns lead the order by, so they must be sortable
+		if !fieldAllows(structField, "sort") {
+			return "", fmt.Errorf("distinct on: %s is not a sortable field", distinct)
+		}
+		if err := b.checkIdent(tableAlias, fieldName); err != nil {
+			return "", fmt.Errorf("distinct on: %w", err)
+		}
+		columns = append(columns, b.column(tableAlias, fieldName))
+		leading = append(leading, SortField{TableAlias: tableAlias, FieldName: fieldName, Direction: ASC})
+	}
+
+	// the distinct columns keep the direction the client sorted them in
+	rest := []SortField{}
+	for _, sort := range b.effectiveSorts() {
+		distinct := false
+		for i := range leading {
+			if leading[i].TableAlias == sort.TableAlias && leading[i].FieldName == sort.FieldName {
+				leading[i].Direction = sort.Direction
+				distinct = true
+			}
+		}
+		if !distinct {
+			rest = append(rest, sort)
+		}
+	}
+	b.Sorts = append(leading, rest...)
+
+	return dialect.DistinctOn(columns), nil
+}
@@ -0,0 +1,82 @@
+package buildsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var namedParamPattern = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Explain inlines the named params into the generated sql for debugging
+// the output is meant for logs and for pasting into a db console; never
+// execute it, always bind the named params instead
+// pass the dialect to escape the string literals for that db
+// example:
+//
+//	where, _, namedParamMap, _ := builder.Build(on, allowed)
+//	log.Println(buildsql.Explain(where, namedParamMap))
+//	// AND p.name LIKE '%cotton%'
+func Explain(query string, namedParamMap map[string]interface{}, dialect ...Dialect) string {
+	quoteString := standardQuoteString
+	if len(dialect) > 0 {
+		if literal, ok := dialect[0].(LiteralDialect); ok {
+			quoteString = literal.QuoteString
+		}
+	}
+
+	return namedParamPattern.ReplaceAllStringFunc(query, func(match string) string {
+		value, ok := namedParamMap[match[1:]]
+		if !ok {
+			return match
+		}
+		return quoteLiteral(value, quoteString)
+	})
+}
+
+// LiteralDialect is implemented by dialects whose string literals
+// don't follow the standard escaping of doubling the quotes
+type LiteralDialect interface {
+	// QuoteString returns s as a quoted string literal
+	QuoteString(s string) string
+}
+
+// QuoteString escapes backslashes too, since mysql treats them
+// as escape characters in string literals by default
+func (mysql) QuoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// standardQuoteString doubles the quotes, backslashes are literal
+func standardQuoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteLiteral renders a bound value as a sql literal
+// strings are quoted by quoteString, so LIKE patterns show
+// their wildcards exactly as the db receives them
+func quoteLiteral(value interface{}, quoteString func(string) string) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err == nil {
+			return quoteLiteral(v, quoteString)
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteString(v)
+	case []byte:
+		return quoteString(string(v))
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05") + "'"
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	}
+	return quoteString(fmt.Sprint(value))
+}
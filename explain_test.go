@@ -0,0 +1,46 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	t.Run("should inline a LIKE pattern with its wildcards quoted", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		where, _, namedParamMap, err := builder.Build("filter=p-name-like-term", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name LIKE '%term%'", buildsql.Explain(where, namedParamMap))
+	})
+
+	t.Run("should escape single quotes and leave numbers bare", func(t *testing.T) {
+		explained := buildsql.Explain("p.name = :name AND p.id = :id AND p.sku = :missing", map[string]interface{}{
+			"name": "O'Brien",
+			"id":   42,
+		})
+		assert.Equal(t, "p.name = 'O''Brien' AND p.id = 42 AND p.sku = :missing", explained)
+	})
+}
+
+func TestExplainDialect(t *testing.T) {
+	namedParamMap := map[string]interface{}{"name": `O'Brien\co`}
+
+	for _, tc := range []struct {
+		name      string
+		dialect   buildsql.Dialect
+		explained string
+	}{
+		{"postgres", buildsql.Postgres, `p.name = 'O''Brien\co'`},
+		{"mysql", buildsql.MySQL, `p.name = 'O\'Brien\\co'`},
+		{"sqlite", buildsql.SQLite, `p.name = 'O''Brien\co'`},
+		{"sqlserver", buildsql.SQLServer, `p.name = 'O''Brien\co'`},
+	} {
+		t.Run("should escape the literal for "+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.explained, buildsql.Explain("p.name = :name", namedParamMap, tc.dialect))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package buildsql
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Filterable is implemented by models declaring their own filter
+// surface: only the columns in the map can be filtered on, and each
+// operator replaces the DefaultOperator of the alias-field-value
+// shorthand on its column; an empty operator keeps the DefaultOperator
+// models without it fall back to their struct tags
+// example:
+//
+//	func (Product) FilterableFields() map[string]buildsql.Operator {
+//		return map[string]buildsql.Operator{"id": buildsql.Equal, "name": buildsql.ILike}
+//	}
+type Filterable interface {
+	FilterableFields() map[string]Operator
+}
+
+// declaredFields returns the fields declared by the model, false when
+// it doesn't implement Filterable on its value or pointer receiver
+func declaredFields(model interface{}) (map[string]Operator, bool) {
+	filterable, ok := model.(Filterable)
+	if !ok {
+		rt := reflect.TypeOf(model)
+		if rt == nil || rt.Kind() != reflect.Struct {
+			return nil, false
+		}
+		if filterable, ok = reflect.New(rt).Interface().(Filterable); !ok {
+			return nil, false
+		}
+	}
+	fields := filterable.FilterableFields()
+	if fields == nil {
+		fields = map[string]Operator{}
+	}
+	return fields, true
+}
+
+// cacheFilterable detects the surface of a registered model once, so
+// builds look it up instead of asserting and allocating per filter
+func (b *QueryBuilder) cacheFilterable(model interface{}) {
+	if b.filterable == nil {
+		b.filterable = make(map[reflect.Type]map[string]Operator)
+	}
+	fields, _ := declaredFields(model)
+	b.filterable[reflect.TypeOf(model)] = fields
+}
+
+// filterableSurfaces returns the declared fields of each alias of the
+// allowed map, detecting models that weren't registered once per build
+func (b *QueryBuilder) filterableSurfaces(allowed map[string]interface{}) map[string]map[string]Operator {
+	surfaces := make(map[string]map[string]Operator)
+	for alias, model := range allowed {
+		fields, cached := b.filterable[reflect.TypeOf(model)]
+		if !cached {
+			fields, _ = declaredFields(model)
+		}
+		if fields != nil {
+			surfaces[alias] = fields
+		}
+	}
+	return surfaces
+}
+
+// isFilterable reports whether the model of the alias allows filters
+// on the field, always true for models without FilterableFields
+func (b *QueryBuilder) isFilterable(alias, field string) bool {
+	fields, ok := b.surfaces[alias]
+	if !ok {
+		return true
+	}
+	_, ok = fields[field]
+	return ok
+}
+
+// applyFilterableOperator gives a shorthand filter the operator its
+// model declares for the column, re-splitting the raw value for it
+func (b *QueryBuilder) applyFilterableOperator(field *FilterField) {
+	if !field.shorthand || field.Operator != b.DefaultOperator {
+		return
+	}
+	fields, ok := b.surfaces[field.TableAlias]
+	if !ok {
+		return
+	}
+	op := fields[field.FieldName]
+	if op == "" || op == field.Operator {
+		return
+	}
+
+	raw, _ := field.Value.(string)
+	if field.Operator.IsLike() {
+		raw = field.Operator.unpattern(raw)
+	}
+	field.Operator = op
+	field.Values = nil
+	field.Value = raw
+	if op.IsMultiValue() {
+		field.Values = strings.Split(raw, ",")
+	}
+	if op.IsLike() {
+		field.Value = op.pattern(raw)
+	}
+}
@@ -0,0 +1,129 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+// Catalog declares its own filter surface
+type Catalog struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+	Tags string `db:"tags"`
+	Cost int64  `db:"cost"`
+}
+
+func (Catalog) FilterableFields() map[string]buildsql.Operator {
+	return map[string]buildsql.Operator{
+		"id":   buildsql.In,
+		"name": buildsql.ILike,
+		"tags": "",
+	}
+}
+
+// Ledger declares it on its pointer receiver
+type Ledger struct {
+	ID     int64 `db:"id"`
+	Amount int64 `db:"amount"`
+}
+
+func (*Ledger) FilterableFields() map[string]buildsql.Operator {
+	return map[string]buildsql.Operator{"id": buildsql.Equal}
+}
+
+// Audited counts the calls to its FilterableFields
+type Audited struct {
+	ID   int64  `db:"id"`
+	Kind string `db:"kind"`
+}
+
+var auditedCalls int
+
+func (Audited) FilterableFields() map[string]buildsql.Operator {
+	auditedCalls++
+	return map[string]buildsql.Operator{"id": buildsql.Equal, "kind": ""}
+}
+
+func TestQueryBuilderFilterable(t *testing.T) {
+	allowed := map[string]interface{}{"c": Catalog{}, "l": Ledger{}, "p": Product{}}
+
+	t.Run("should drop filters on fields the model doesn't declare", func(t *testing.T) {
+		where, _, _, err := buildsql.NewQueryBuilder().Build("filter=c-cost-gt-10&filter=c-tags-eq-red", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND c.tags = :filter_c_tags_0", where)
+	})
+
+	t.Run("should use the declared operator for the shorthand", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultOperator = buildsql.Equal
+		where, _, namedParamMap, err := builder.Build("filter=c-name-cot&filter=c-id-1,2&filter=c-tags-red", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND c.name ILIKE :filter_c_name_0 AND c.id IN (:filter_c_id_0_0, :filter_c_id_0_1) AND c.tags = :filter_c_tags_0", where)
+		assert.Equal(t, "%cot%", namedParamMap["filter_c_name_0"])
+		assert.Equal(t, "red", namedParamMap["filter_c_tags_0"])
+	})
+
+	t.Run("should keep an explicit operator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DefaultOperator = buildsql.Equal
+		where, _, _, err := builder.Build("filter=c-name-eq-cotton", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND c.name = :filter_c_name_0", where)
+	})
+
+	t.Run("should detect the pointer receiver", func(t *testing.T) {
+		where, _, _, err := buildsql.NewQueryBuilder().Build("filter=l-amount-gt-10&filter=l-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND l.id = :filter_l_id_0", where)
+	})
+
+	t.Run("should fall back to the struct tags", func(t *testing.T) {
+		where, _, _, err := buildsql.NewQueryBuilder().Build("filter=p-amount-gt-10", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.amount > :filter_p_amount_0", where)
+	})
+
+	t.Run("should detect the fields of a registered model once", func(t *testing.T) {
+		auditedCalls = 0
+		builder := buildsql.NewQueryBuilder().WithAllowedFields(map[string]interface{}{"a": Audited{}})
+
+		for i := 0; i < 3; i++ {
+			where, _, _, err := builder.Build("filter=a-id-eq-1&filter=a-kind-eq-x", nil)
+			assert.Nil(t, err)
+			assert.Equal(t, " AND a.id = :filter_a_id_0 AND a.kind = :filter_a_kind_0", where)
+		}
+		assert.Equal(t, 1, auditedCalls)
+	})
+
+	t.Run("should reuse the fields detected by RegisterModel", func(t *testing.T) {
+		auditedCalls = 0
+		buildsql.RegisterModel("audited", Audited{})
+
+		for i := 0; i < 3; i++ {
+			builder, err := buildsql.NewQueryBuilderFromRegistry("audited")
+			assert.Nil(t, err)
+			where, _, _, err := builder.Build("filter=audited-id-eq-1&filter=audited-kind-eq-x", nil)
+			assert.Nil(t, err)
+			assert.Equal(t, " AND audited.id = :filter_audited_id_0 AND audited.kind = :filter_audited_kind_0", where)
+		}
+		assert.Equal(t, 1, auditedCalls)
+	})
+
+	t.Run("should reject havings on fields the model doesn't declare", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("having=sum-c-cost-gt-10"))
+
+		_, _, err := builder.BuildHaving(allowed)
+		assert.EqualError(t, err, "having: c.cost is not allowed")
+	})
+
+	t.Run("should report undeclared fields in lenient builds", func(t *testing.T) {
+		_, _, _, rejected, err := buildsql.NewQueryBuilder().BuildLenient("filter=c-cost-gt-10", allowed)
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(rejected)) {
+			assert.Equal(t, "c.cost can't be filtered on", rejected[0].Reason)
+		}
+	})
+}
@@ -1,13 +1,19 @@
 package buildsql
 
 import (
-	"fmt"
+	"net/url"
 	"strings"
 )
 
 // FilterBuilder struct
 type FilterBuilder struct {
+	// Delimiter joins the alias, field, operator and value, it must
+	// match the Delimiter of the QueryBuilder parsing the string; empty
+	// falls back to the package Delimiter
+	Delimiter string
+
 	prefixes []string
+	keys     []string
 	filters  map[string]string
 	sorts    []string
 }
@@ -16,21 +22,57 @@ type FilterBuilder struct {
 func NewFilterBuilder() *FilterBuilder {
 	return &FilterBuilder{
 		prefixes: make([]string, 0),
+		keys:     make([]string, 0),
 		filters:  make(map[string]string),
 		sorts:    []string{},
 	}
 }
 
 // AddFilter adds a filter to the filter builder
+// operators the parser can't read back, like or, are dropped
 func (fb *FilterBuilder) AddFilter(prefix, fieldName string, operator Operator, value string) *FilterBuilder {
-	filterKey := fmt.Sprintf("%s-%s-%s", prefix, fieldName, operator)
-	if fb.isValidFilter(filterKey) {
+	filterKey := strings.Join([]string{prefix, fieldName, string(operator)}, fb.delimiter())
+	if fb.isValidFilter(filterKey) && roundTrips(operator) {
+		if _, ok := fb.filters[filterKey]; !ok {
+			fb.keys = append(fb.keys, filterKey)
+		}
 		fb.filters[filterKey] = value
 		fb.prefixes = append(fb.prefixes, prefix)
 	}
 	return fb
 }
 
+// AddBetween adds a btw filter, values containing a comma are dropped
+// since they would split into more than two values
+func (fb *FilterBuilder) AddBetween(prefix, fieldName, from, to string) *FilterBuilder {
+	if strings.Contains(from, ",") || strings.Contains(to, ",") {
+		return fb
+	}
+	return fb.AddFilter(prefix, fieldName, Between, from+","+to)
+}
+
+// AddIn adds a comma separated list filter for in, notin,
+// contains or ncontains
+func (fb *FilterBuilder) AddIn(prefix, fieldName string, operator Operator, values ...string) *FilterBuilder {
+	if !operator.IsMultiValue() || operator.IsBetween() || operator.IsNotBetween() || operator == SimilarAbove || len(values) == 0 {
+		return fb
+	}
+	for _, v := range values {
+		if strings.Contains(v, ",") {
+			return fb
+		}
+	}
+	return fb.AddFilter(prefix, fieldName, operator, strings.Join(values, ","))
+}
+
+// AddNull adds an isnull or isnotnull filter
+func (fb *FilterBuilder) AddNull(prefix, fieldName string, operator Operator) *FilterBuilder {
+	if !operator.IsNull() {
+		return fb
+	}
+	return fb.AddFilter(prefix, fieldName, operator, "")
+}
+
 // AddSort adds a sort to the filter builder
 func (fb *FilterBuilder) AddSort(prefix, fieldName string, direction ...SortDirection) *FilterBuilder {
 	if len(direction) == 0 {
@@ -41,14 +83,14 @@ func (fb *FilterBuilder) AddSort(prefix, fieldName string, direction ...SortDire
 	if direction[0] == DESC {
 		dir = "-"
 	}
-	sortKey := fmt.Sprintf("%s%s-%s", dir, prefix, fieldName)
+	sortKey := dir + prefix + fb.delimiter() + fieldName
 	fb.sorts = append(fb.sorts, sortKey)
 	return fb
 }
 
 // isValidFilter validates the filter format
 func (fb *FilterBuilder) isValidFilter(filter string) bool {
-	parts := strings.Split(filter, Delimiter)
+	parts := strings.Split(filter, fb.delimiter())
 	for _, part := range parts {
 		if part == "" {
 			return false
@@ -57,18 +99,33 @@ func (fb *FilterBuilder) isValidFilter(filter string) bool {
 	return len(parts) == 3
 }
 
+// delimiter returns Delimiter, defaulting to the package Delimiter
+func (fb *FilterBuilder) delimiter() string {
+	if fb.Delimiter == "" {
+		return Delimiter
+	}
+	return fb.Delimiter
+}
+
+// roundTrips reports whether a filter with the operator parses back
+// into the same operator and renders a condition
+func roundTrips(operator Operator) bool {
+	return operator.IsValid() && operator.Convert() != ""
+}
+
 // String constructs the final query string
+// filters keep the order they were added in
 func (fb *FilterBuilder) String() string {
 	var queryString strings.Builder
 
 	// Add filters to the query string
-	for field, value := range fb.filters {
-		queryString.WriteString(fmt.Sprintf("filter=%s-%s&", field, value))
+	for _, key := range fb.keys {
+		queryString.WriteString("filter=" + url.QueryEscape(key+fb.delimiter()+fb.filters[key]) + "&")
 	}
 
 	// Add sorts to the query string
 	for _, sort := range fb.sorts {
-		queryString.WriteString(fmt.Sprintf("sortOn=%s&", sort))
+		queryString.WriteString("sortOn=" + url.QueryEscape(sort) + "&")
 	}
 
 	// Remove the trailing '&' if it exists
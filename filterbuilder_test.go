@@ -63,4 +63,86 @@ func TestFilterBuilder(t *testing.T) {
 		fmt.Println("fb.String()", fb.String())
 		assert.Equal(t, expected, fb.String())
 	})
+
+	t.Run("AddBetween, AddIn and AddNull should add multi value and null filters", func(t *testing.T) {
+		fb := buildsql.NewFilterBuilder()
+		fb.AddBetween("pr", "amount", "10", "20")
+		fb.AddIn("p", "id", buildsql.In, "1", "2")
+		fb.AddNull("p", "sku", buildsql.IsNull)
+		assert.Equal(t, "filter=pr-amount-btw-10%2C20&filter=p-id-in-1%2C2&filter=p-sku-isnull-", fb.String())
+	})
+
+	t.Run("AddIn and AddNull should drop mismatched operators", func(t *testing.T) {
+		fb := buildsql.NewFilterBuilder()
+		fb.AddIn("p", "id", buildsql.Equal, "1", "2")
+		fb.AddIn("p", "id", buildsql.Between, "1", "2")
+		fb.AddNull("p", "sku", buildsql.Equal)
+		fb.AddBetween("pr", "amount", "1,5", "20")
+		assert.Equal(t, "", fb.String())
+	})
+}
+
+func TestFilterBuilderRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		operator buildsql.Operator
+		value    string
+		accepted bool
+	}{
+		{buildsql.Equal, "a", true},
+		{buildsql.NotEqual, "a", true},
+		{buildsql.Like, "a", true},
+		{buildsql.LikeStart, "a", true},
+		{buildsql.LikeEnd, "a", true},
+		{buildsql.ILike, "a", true},
+		{buildsql.OrLike, "a", true},
+		{buildsql.OrILike, "a", true},
+		{buildsql.NotLike, "a", true},
+		{buildsql.NotILike, "a", true},
+		{buildsql.LessThan, "1", true},
+		{buildsql.LessThanOrEqual, "1", true},
+		{buildsql.GreaterThan, "1", true},
+		{buildsql.GreaterThanOrEqual, "1", true},
+		{buildsql.Between, "1,2", true},
+		{buildsql.Or, "a", false},
+		{buildsql.In, "1,2", true},
+		{buildsql.NotIn, "1,2", true},
+		{buildsql.IsNull, "", true},
+		{buildsql.IsNotNull, "", true},
+		{buildsql.AnyEqual, "a", true},
+		{buildsql.Contains, "a,b", true},
+		{buildsql.NotContains, "a,b", true},
+		{buildsql.Empty, "", true},
+		{buildsql.NotEmpty, "", true},
+		{buildsql.IsTrue, "", true},
+		{buildsql.IsNotFalse, "", true},
+		{buildsql.Operator("bogus"), "a", false},
+	} {
+		t.Run("should round trip "+tc.operator.String(), func(t *testing.T) {
+			fb := buildsql.NewFilterBuilder()
+			fb.AddFilter("p", "name", tc.operator, tc.value)
+			if !tc.accepted {
+				assert.Equal(t, "", fb.String())
+				return
+			}
+
+			builder := buildsql.NewQueryBuilder()
+			err := builder.ParseParamString(fb.String())
+			assert.Nil(t, err)
+			if assert.Equal(t, 1, len(builder.Filters)) {
+				assert.Equal(t, "p", builder.Filters[0].TableAlias)
+				assert.Equal(t, "name", builder.Filters[0].FieldName)
+				assert.Equal(t, tc.operator, builder.Filters[0].Operator)
+			}
+		})
+	}
+
+	t.Run("should round trip values with reserved characters", func(t *testing.T) {
+		fb := buildsql.NewFilterBuilder()
+		fb.AddFilter("p", "name", buildsql.Equal, "a&b=c d-e")
+
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString(fb.String())
+		assert.Nil(t, err)
+		assert.Equal(t, "a&b=c d-e", builder.Filters[0].Value)
+	})
 }
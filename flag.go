@@ -0,0 +1,17 @@
+package buildsql
+
+// flagToggle is a registered flag a client toggled on with flag=name
+// or negated with flag=-name
+type flagToggle struct {
+	name   string
+	negate bool
+}
+
+// render parenthesizes the flag predicate so its own AND and OR
+// can't mix with the surrounding conditions
+func (t flagToggle) render(predicate string) string {
+	if t.negate {
+		return "NOT (" + predicate + ")"
+	}
+	return "(" + predicate + ")"
+}
@@ -0,0 +1,53 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderFlags(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	flags := map[string]string{
+		"overdue": "p.due_date < NOW() AND p.paid = false",
+	}
+
+	t.Run("should AND in an activated flag", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Flags = flags
+
+		where, _, namedParamMap, err := builder.Build("filter=p-name-eq-gloves&flag=overdue", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.name = :filter_p_name_0 AND (p.due_date < NOW() AND p.paid = false)", where)
+		assert.Equal(t, 1, len(namedParamMap))
+	})
+
+	t.Run("should negate a flag with a '-' prefix", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Flags = flags
+
+		where, _, _, err := builder.Build("flag=-overdue", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND NOT (p.due_date < NOW() AND p.paid = false)", where)
+	})
+
+	t.Run("should reject an unregistered flag", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Flags = flags
+
+		_, _, _, err := builder.Build("flag=archived", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should not leak flags across builds", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Flags = flags
+
+		_, _, _, err := builder.Build("flag=overdue", allowed)
+		assert.Nil(t, err)
+		where, _, _, err := builder.Build("", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+	})
+}
@@ -0,0 +1,61 @@
+package buildsql
+
+// WithDialect sets the Dialect
+func (b *QueryBuilder) WithDialect(dialect Dialect) *QueryBuilder {
+	b.Dialect = dialect
+	return b
+}
+
+// WithAllowedFields registers the structs keyed by table alias, used
+// by Build when it's called with a nil allowed map
+func (b *QueryBuilder) WithAllowedFields(allowed map[string]interface{}) *QueryBuilder {
+	if b.Tables == nil {
+		b.Tables = make(map[string]interface{})
+	}
+	for alias, model := range allowed {
+		b.Tables[alias] = model
+		b.cacheFilterable(model)
+	}
+	return b
+}
+
+// WithLimit sets MaxLimit, the cap on the limit of the paginators
+// passed to Build
+func (b *QueryBuilder) WithLimit(max int) *QueryBuilder {
+	b.MaxLimit = max
+	return b
+}
+
+// WithTagName sets TagName, the struct tag holding the column names
+func (b *QueryBuilder) WithTagName(tagName string) *QueryBuilder {
+	b.TagName = tagName
+	return b
+}
+
+// WithDefaultSort sets the DefaultSort
+func (b *QueryBuilder) WithDefaultSort(sorts ...SortField) *QueryBuilder {
+	b.DefaultSort = sorts
+	return b
+}
+
+// WithDelimiter sets the Delimiter of the filter, sort and having params
+func (b *QueryBuilder) WithDelimiter(delimiter string) *QueryBuilder {
+	b.Delimiter = delimiter
+	return b
+}
+
+// delimiter returns Delimiter, defaulting to the package Delimiter
+func (b *QueryBuilder) delimiter() string {
+	if b.Delimiter == "" {
+		return Delimiter
+	}
+	return b.Delimiter
+}
+
+// tagName returns TagName, defaulting to the sqlx 'db' tag
+func (b *QueryBuilder) tagName() string {
+	if b.TagName == "" {
+		return "db"
+	}
+	return b.TagName
+}
@@ -0,0 +1,85 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+type Listing struct {
+	ID    int64  `col:"id"`
+	Title string `col:"title"`
+}
+
+func TestQueryBuilderFluent(t *testing.T) {
+	t.Run("should build with a fluently constructed builder", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().
+			WithDialect(buildsql.Postgres).
+			WithAllowedFields(map[string]interface{}{"p": Product{}}).
+			WithDefaultSort(buildsql.SortField{TableAlias: "p", FieldName: "id", Direction: buildsql.DESC}).
+			WithLimit(100)
+
+		where, orderBy, namedParamMap, err := builder.Build("filter=p-name-eq-gloves", nil, &buildsql.Paginator{Limit: 1000})
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "p"."name" = :filter_p_name_0`, where)
+		assert.Equal(t, `ORDER BY "p"."id" DESC LIMIT 100`, orderBy)
+		assert.Equal(t, "gloves", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should resolve fields through a custom tag name", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().WithTagName("col")
+
+		where, _, _, err := builder.Build("filter=l-title-eq-loft", map[string]interface{}{"l": Listing{}})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND l.title = :filter_l_title_0", where)
+	})
+
+	t.Run("should keep direct field access working", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().WithDialect(buildsql.MySQL)
+		builder.Dialect = buildsql.SQLServer
+
+		where, _, _, err := builder.Build("filter=p-id-eq-1", map[string]interface{}{"p": Product{}})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND [p].[id] = :filter_p_id_0", where)
+	})
+}
+
+type Untagged struct {
+	ID        int64
+	FirstName string
+	UserID    int64
+	HTTPCode  int
+	Email     string `db:"email_address"`
+}
+
+func TestQueryBuilderInferColumnFromFieldName(t *testing.T) {
+	allowed := map[string]interface{}{"u": Untagged{}}
+
+	t.Run("should snake case the untagged go field names", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.InferColumnFromFieldName = true
+
+		where, orderBy, _, err := builder.Build("filter=u-first_name-eq-bob&filter=u-user_id-eq-7&filter=u-http_code-eq-200&sortOn=-u-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.first_name = :filter_u_first_name_0 AND u.user_id = :filter_u_user_id_0 AND u.http_code = :filter_u_http_code_0", where)
+		assert.Equal(t, "ORDER BY u.id DESC", orderBy)
+	})
+
+	t.Run("should prefer the tag when it's present", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.InferColumnFromFieldName = true
+
+		where, _, _, err := builder.Build("filter=u-email-eq-a&filter=u-email_address-eq-b", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.email_address = :filter_u_email_address_0", where)
+	})
+
+	t.Run("should drop untagged fields by default", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=u-first_name-eq-bob", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+	})
+}
@@ -0,0 +1,73 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// Full text search
+//
+// https://example.org/?q=cotton gloves&sortOn=-relevance
+//
+// q searches the FullTextColumns using the dialect's full text syntax
+// the relevance sort token orders by the match score and is only
+// valid alongside q
+//
+
+// RelevanceSort is the sort token ordering by full text relevance
+const RelevanceSort = "relevance"
+
+const searchParam = "search_q"
+
+// FullTextDialect is implemented by dialects that support full text search
+type FullTextDialect interface {
+	// FullTextMatch returns the predicate matching the columns against the param
+	FullTextMatch(columns []string, param string) string
+	// FullTextRank returns the relevance score of the columns for the param
+	FullTextRank(columns []string, param string) string
+}
+
+func (postgres) FullTextMatch(columns []string, param string) string {
+	return fmt.Sprintf("to_tsvector(concat_ws(' ', %s)) @@ plainto_tsquery(%s)", strings.Join(columns, ", "), param)
+}
+
+func (postgres) FullTextRank(columns []string, param string) string {
+	return fmt.Sprintf("ts_rank(to_tsvector(concat_ws(' ', %s)), plainto_tsquery(%s))", strings.Join(columns, ", "), param)
+}
+
+func (mysql) FullTextMatch(columns []string, param string) string {
+	return fmt.Sprintf("MATCH (%s) AGAINST (%s IN NATURAL LANGUAGE MODE)", strings.Join(columns, ", "), param)
+}
+
+func (mysql) FullTextRank(columns []string, param string) string {
+	return mysql{}.FullTextMatch(columns, param)
+}
+
+// fullTextDialect returns the dialect when it supports full text search
+func (b *QueryBuilder) fullTextDialect() (FullTextDialect, error) {
+	dialect, ok := b.Dialect.(FullTextDialect)
+	if !ok {
+		return nil, fmt.Errorf("q: full text search is not supported by the dialect")
+	}
+	return dialect, nil
+}
+
+func (b *QueryBuilder) fullTextMatch() (string, error) {
+	dialect, err := b.fullTextDialect()
+	if err != nil {
+		return "", err
+	}
+	return dialect.FullTextMatch(b.FullTextColumns, ":"+b.paramName(searchParam)), nil
+}
+
+func (b *QueryBuilder) fullTextRank() (string, error) {
+	if b.Search == "" || len(b.FullTextColumns) == 0 {
+		return "", fmt.Errorf("sortOn: %s requires a full text q search", RelevanceSort)
+	}
+	dialect, err := b.fullTextDialect()
+	if err != nil {
+		return "", err
+	}
+	return dialect.FullTextRank(b.FullTextColumns, ":"+b.paramName(searchParam)), nil
+}
@@ -0,0 +1,55 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderFullText(t *testing.T) {
+	allowed := map[string]interface{}{
+		"p": Product{},
+	}
+
+	t.Run("should order by ts_rank under postgres", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+		builder.FullTextColumns = []string{"p.name", "p.slug"}
+
+		where, orderBy, namedParamMap, err := builder.Build("q=cotton gloves&sortOn=-relevance&sortOn=p-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND to_tsvector(concat_ws(' ', p.name, p.slug)) @@ plainto_tsquery(:search_q)", where)
+		assert.Equal(t, `ORDER BY ts_rank(to_tsvector(concat_ws(' ', p.name, p.slug)), plainto_tsquery(:search_q)) DESC, "p"."id" ASC`, orderBy)
+		assert.Equal(t, "cotton gloves", namedParamMap["search_q"])
+	})
+
+	t.Run("should order by the match score under mysql", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+		builder.FullTextColumns = []string{"p.name"}
+
+		where, orderBy, _, err := builder.Build("q=cotton&sortOn=-relevance", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND MATCH (p.name) AGAINST (:search_q IN NATURAL LANGUAGE MODE)", where)
+		assert.Equal(t, "ORDER BY MATCH (p.name) AGAINST (:search_q IN NATURAL LANGUAGE MODE) DESC", orderBy)
+	})
+
+	t.Run("should reject a relevance sort without a search", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+		builder.FullTextColumns = []string{"p.name"}
+
+		_, _, _, err := builder.Build("sortOn=-relevance", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a search under a dialect without full text support", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.SQLServer
+		builder.FullTextColumns = []string{"p.name"}
+
+		_, _, _, err := builder.Build("q=cotton", allowed)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,27 @@
+package buildsql
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var functionColumnPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// isFunctionField reports whether alias.field is a registered function field
+func (b *QueryBuilder) isFunctionField(tableAlias, fieldName string) bool {
+	_, ok := b.FunctionFields[fmt.Sprintf("%s.%s", tableAlias, fieldName)]
+	return ok
+}
+
+// filterColumn renders the column a filter compares against
+// function fields expand their template, with each {column}
+// rendered as a column of the filter's alias
+func (b *QueryBuilder) filterColumn(tableAlias, fieldName string) string {
+	template, ok := b.FunctionFields[fmt.Sprintf("%s.%s", tableAlias, fieldName)]
+	if !ok {
+		return b.column(tableAlias, fieldName)
+	}
+	return functionColumnPattern.ReplaceAllStringFunc(template, func(match string) string {
+		return b.column(tableAlias, match[1:len(match)-1])
+	})
+}
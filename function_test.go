@@ -0,0 +1,47 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderFunctionFields(t *testing.T) {
+	allowed := map[string]interface{}{"u": User{}}
+
+	t.Run("should expand a registered function field", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.FunctionFields = map[string]string{
+			"u.age": "date_part('year', age({created_at}))",
+		}
+
+		where, _, namedParamMap, err := builder.Build("filter=u-age-gt-18", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND date_part('year', age(u.created_at)) > :filter_u_age_0", where)
+		assert.Equal(t, "18", namedParamMap["filter_u_age_0"])
+	})
+
+	t.Run("should quote the template columns with a dialect", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+		builder.FunctionFields = map[string]string{
+			"u.full_name": "concat_ws(' ', {first_name}, {last_name})",
+		}
+
+		where, _, _, err := builder.Build("filter=u-full_name-ilike-jane", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND concat_ws(' ', "u"."first_name", "u"."last_name") ILIKE :filter_u_full_name_0`, where)
+	})
+
+	t.Run("should ignore a function field of an alias that isn't allowed", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.FunctionFields = map[string]string{
+			"x.age": "age({created_at})",
+		}
+
+		where, _, _, err := builder.Build("filter=x-age-gt-18", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+	})
+}
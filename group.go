@@ -0,0 +1,172 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// OR and AND groups combine filters on different fields, and nest
+//
+// filter=or:(u-first_name-like-bob,u-email-like-bob)&filter=u-active-eq-true
+// (u.first_name LIKE :filter_u_first_name_0 OR u.email LIKE :filter_u_email_0) AND u.active = :filter_u_active_0
+//
+// filter=or:(and:(u-id-gt-10,u-email-isnull),u-first_name-eq-bob)
+// ((u.id > :filter_u_id_0 AND u.email IS NULL) OR u.first_name = :filter_u_first_name_0)
+//
+
+// groupMembers returns the combinator and the members of an or:(...)
+// or and:(...) filter, the group must close at the end of the filter
+func groupMembers(filter string) (combinator, members string, ok bool, err error) {
+	for _, c := range []string{"or", "and"} {
+		if !strings.HasPrefix(filter, c+":(") {
+			continue
+		}
+		inner := filter[len(c)+1:]
+		depth := 0
+		for i, r := range inner {
+			switch r {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 && i < len(inner)-1 {
+				return "", "", false, fmt.Errorf("%q closes its group before the end", filter)
+			}
+		}
+		if depth != 0 {
+			return "", "", false, fmt.Errorf("%q has an unclosed group", filter)
+		}
+		return c, inner[1 : len(inner)-1], true, nil
+	}
+	return "", "", false, nil
+}
+
+// splitGroup splits the members on the commas outside of nested groups,
+// except the commas of an in or btw list which stay part of its value up
+// to the next piece starting with an explicit operator or a group, e.g.
+// p-id-in-1,2,p-name-eq-x is two members
+func splitGroup(members, delimiter string) ([]string, error) {
+	pieces := []string{}
+	depth, start := 0, 0
+	for i, r := range members {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				pieces = append(pieces, members[start:i])
+				start = i + 1
+			}
+		}
+		if depth < 0 {
+			return nil, fmt.Errorf("%q has an orphaned )", members)
+		}
+	}
+	pieces = append(pieces, members[start:])
+
+	filters := []string{}
+	multiValue := false
+	for _, piece := range pieces {
+		piece = strings.TrimSpace(piece)
+		_, _, isGroup, _ := groupMembers(piece)
+		parts := strings.SplitN(piece, delimiter, 4)
+		startsFilter := isGroup || len(parts) >= 3 && Operator(parts[2]).IsValid()
+		if multiValue && !startsFilter {
+			filters[len(filters)-1] += "," + piece
+			continue
+		}
+		filters = append(filters, piece)
+		multiValue = !isGroup && startsFilter && Operator(parts[2]).IsMultiValue()
+	}
+	return filters, nil
+}
+
+// parseMember parses a filter, or the members of a group into filters
+// sharing its group path, e.g. or:3.and:4 for an and group nested in
+// an or group; the ids are the index of the group's first filter
+func (b *QueryBuilder) parseMember(index int, group, filter string) error {
+	combinator, members, ok, err := groupMembers(filter)
+	if err != nil {
+		return fmt.Errorf("filter[%d]: %w", index, err)
+	}
+	if !ok {
+		filterField, err := b.parseFilter(index, filter)
+		if err != nil {
+			return err
+		}
+		filterField.group = group
+		b.Filters = append(b.Filters, filterField)
+		b.SearchTables[filterField.TableAlias] = 1
+		return nil
+	}
+
+	id := fmt.Sprintf("%s:%d", combinator, len(b.Filters))
+	if group != "" {
+		id = group + "." + id
+	}
+	pieces, err := splitGroup(members, b.delimiter())
+	if err != nil {
+		return fmt.Errorf("filter[%d]: %w", index, err)
+	}
+	for _, piece := range pieces {
+		if piece == "" {
+			return fmt.Errorf("filter[%d]: %q has an empty member", index, filter)
+		}
+		if err := b.parseMember(index, id, piece); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupKey is the where key shared by every member of the top level group
+func groupKey(group string) string {
+	key, _, _ := strings.Cut(group, ".")
+	return key
+}
+
+// renderGroup renders the wheres of the group at path as one condition,
+// the members and nested groups joined by the group's combinator in the
+// order they were sent
+func renderGroup(path string, wheres []Where) string {
+	join := " OR "
+	if strings.HasPrefix(path[strings.LastIndex(path, ".")+1:], "and:") {
+		join = " AND "
+	}
+
+	conditions := []string{}
+	nested := map[string][]Where{}
+	order := []string{}
+	for _, w := range wheres {
+		if w.group == path {
+			conditions = append(conditions, w.SqlString)
+			order = append(order, "")
+			continue
+		}
+		next, _, _ := strings.Cut(strings.TrimPrefix(w.group, path+"."), ".")
+		child := path + "." + next
+		if _, ok := nested[child]; !ok {
+			order = append(order, child)
+		}
+		nested[child] = append(nested[child], w)
+	}
+
+	// the nested groups take the place of their first member
+	rendered := []string{}
+	for _, child := range order {
+		if child == "" {
+			rendered = append(rendered, conditions[0])
+			conditions = conditions[1:]
+			continue
+		}
+		rendered = append(rendered, renderGroup(child, nested[child]))
+	}
+	if len(rendered) == 1 {
+		return rendered[0]
+	}
+	return "(" + strings.Join(rendered, join) + ")"
+}
@@ -0,0 +1,113 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderOrGroups(t *testing.T) {
+	allowed := map[string]interface{}{"u": User{}}
+
+	t.Run("should OR the members of a group across fields", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=or:(u-first_name-like-bob,u-email-like-bob)&filter=u-email_visibility-eq-true", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (u.first_name LIKE :filter_u_first_name_0 OR u.email LIKE :filter_u_email_0) AND u.email_visibility = :filter_u_email_visibility_0", where)
+		assert.Equal(t, map[string]interface{}{
+			"filter_u_first_name_0":       "%bob%",
+			"filter_u_email_0":            "%bob%",
+			"filter_u_email_visibility_0": "true",
+		}, namedParamMap)
+	})
+
+	t.Run("should keep the commas of in and btw lists in their member", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=or:(u-id-in-1,2,3, u-email-isnull,u-id-btw-10,20)", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (u.id IN (:filter_u_id_0_0, :filter_u_id_0_1, :filter_u_id_0_2) OR u.email IS NULL OR u.id BETWEEN :filter_u_id_1_0 AND :filter_u_id_1_1)", where)
+		assert.Equal(t, 5, len(namedParamMap))
+	})
+
+	t.Run("should keep separate groups and same field filters apart", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=or:(u-id-eq-1,u-email-eq-a)&filter=or:(u-id-eq-2,u-first_name-eq-b)&filter=u-email-eq-c", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (u.id = :filter_u_id_0 OR u.email = :filter_u_email_0) AND (u.id = :filter_u_id_1 OR u.first_name = :filter_u_first_name_0) AND u.email = :filter_u_email_1", where)
+	})
+
+	t.Run("should group under the OR combinator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, _, err := builder.Build("filter=or:(u-id-eq-1,u-email-eq-a)&filter=u-email_visibility-eq-true&combinator=and", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (u.id = :filter_u_id_0 OR u.email = :filter_u_email_0) AND u.email_visibility = :filter_u_email_visibility_0", where)
+	})
+
+	t.Run("should return member parse errors", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.Build("filter=or:(u-id-eq-1,u)", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderNestedGroups(t *testing.T) {
+	allowed := map[string]interface{}{"u": User{}}
+
+	t.Run("should render an and group nested in an or group", func(t *testing.T) {
+		where, _, namedParamMap, err := buildsql.NewQueryBuilder().Build("filter=or:(and:(u-id-gt-10,u-email-isnull),u-first_name-eq-bob)&filter=u-email_visibility-eq-true", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND ((u.id > :filter_u_id_0 AND u.email IS NULL) OR u.first_name = :filter_u_first_name_0) AND u.email_visibility = :filter_u_email_visibility_0", where)
+		assert.Equal(t, 3, len(namedParamMap))
+	})
+
+	t.Run("should render two levels of nesting with mixed combinators", func(t *testing.T) {
+		where, _, _, err := buildsql.NewQueryBuilder().Build("filter=and:(u-email_visibility-eq-true,or:(u-first_name-eq-a,and:(u-id-in-1,2,u-last_name-eq-b)),u-username-like-c)", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND (u.email_visibility = :filter_u_email_visibility_0 AND (u.first_name = :filter_u_first_name_0 OR (u.id IN (:filter_u_id_0_0, :filter_u_id_0_1) AND u.last_name = :filter_u_last_name_0)) AND u.username LIKE :filter_u_username_0)", where)
+	})
+
+	t.Run("should keep sibling groups apart", func(t *testing.T) {
+		where, _, _, err := buildsql.NewQueryBuilder().Build("filter=or:(and:(u-id-eq-1,u-email-eq-a),and:(u-id-eq-2,u-email-eq-b))", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND ((u.id = :filter_u_id_0 AND u.email = :filter_u_email_0) OR (u.id = :filter_u_id_1 AND u.email = :filter_u_email_1))", where)
+	})
+
+	t.Run("should render a single member group without parentheses", func(t *testing.T) {
+		where, _, _, err := buildsql.NewQueryBuilder().Build("filter=or:(and:(u-id-eq-1))", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND u.id = :filter_u_id_0", where)
+	})
+
+	t.Run("should reject unclosed and orphaned groups", func(t *testing.T) {
+		for _, on := range []string{
+			"filter=or:(u-id-eq-1,and:(u-id-eq-2)",
+			"filter=or:(u-id-eq-1),u-id-eq-2)",
+			"filter=or:(u-id-eq-1,u-id-eq-2))",
+			"filter=or:()",
+			"filter=or:(u-id-eq-1,,u-id-eq-2)",
+		} {
+			_, _, _, err := buildsql.NewQueryBuilder().Build(on, allowed)
+			assert.NotNil(t, err, on)
+		}
+	})
+
+	t.Run("should restore nested groups from a snapshot", func(t *testing.T) {
+		on := "filter=or:(and:(u-id-gt-10,u-email-isnull),u-first_name-eq-bob)"
+		saved := buildsql.NewQueryBuilder()
+		assert.Nil(t, saved.ParseParamString(on))
+		data, err := saved.Snapshot()
+		assert.Nil(t, err)
+
+		restored := buildsql.NewQueryBuilder()
+		assert.Nil(t, restored.Restore(data))
+		where, _, _, err := restored.Build("", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND ((u.id > :filter_u_id_0 AND u.email IS NULL) OR u.first_name = :filter_u_first_name_0)", where)
+	})
+}
@@ -0,0 +1,167 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// HAVING filters apply a condition to an aggregate of a field
+//
+// having: field format is: 'aggregate' 'hyphen' 'table prefix' 'hyphen' 'fieldname' 'hyphen' 'operator' 'hyphen' 'field value'
+// Example: sum-pr-amount-gt-100
+// sum-pr-amount-gt-100 =	 sum      -     pr       -     amount     -     gt      -    100
+// 							  |		  |		 |		 |		 |		  |		|		|	  |
+// 						aggregate  hyphen  prefix  hyphen fieldName hyphen operator hyphen value
+//
+// https://example.org/?filter=p-name-like-cotton&having=sum-pr-amount-gt-100
+//
+
+// Aggregate is a SQL aggregate function usable in a HAVING filter
+type Aggregate string
+
+const (
+	Count Aggregate = "count"
+	Sum   Aggregate = "sum"
+	Avg   Aggregate = "avg"
+	Min   Aggregate = "min"
+	Max   Aggregate = "max"
+)
+
+// IsValid reports whether the aggregate is a known aggregate function
+func (a Aggregate) IsValid() bool {
+	switch a {
+	case Count, Sum, Avg, Min, Max:
+		return true
+	}
+	return false
+}
+
+// HavingField is a filter applied to an aggregate
+type HavingField struct {
+	Aggregate Aggregate
+	FilterField
+}
+
+// AddHavingCount adds a HAVING COUNT(*) condition for group cardinality
+// e.g. groups with at least n members; it requires GroupBy
+func (b *QueryBuilder) AddHavingCount(op Operator, n int) *QueryBuilder {
+	field := HavingField{Aggregate: Count}
+	field.FieldName = "*"
+	field.Operator = op
+	field.Value = n
+	b.Havings = append(b.Havings, field)
+	return b
+}
+
+// parseHaving parses a single having token
+func parseHaving(having, delimiter string) (HavingField, error) {
+	having = strings.TrimSpace(having)
+	parts := strings.SplitN(having, delimiter, 5)
+
+	var field HavingField
+	if len(parts) < 4 {
+		return field, fmt.Errorf("having: %s has too few params", having)
+	}
+
+	field.Aggregate = Aggregate(strings.ToLower(parts[0]))
+	if !field.Aggregate.IsValid() {
+		return field, fmt.Errorf("having: %s is not a valid aggregate", parts[0])
+	}
+
+	field.TableAlias = parts[1]
+	field.FieldName = parts[2]
+	field.Operator = Operator(parts[3])
+	if !field.Operator.IsAggregateSafe() {
+		return field, fmt.Errorf("having: %s cannot be applied to an aggregate", field.Operator)
+	}
+
+	if field.Operator.IsNull() {
+		return field, nil
+	}
+
+	if len(parts) < 5 {
+		return field, fmt.Errorf("having: %s has too few params", having)
+	}
+
+	if field.Operator.IsMultiValue() {
+		field.Values = strings.Split(parts[4], ",")
+	} else {
+		field.Value = parts[4]
+	}
+	return field, nil
+}
+
+// BuildHaving renders the HAVING clause from the parsed having filters
+// call it after ParseParamString, or use BuildSelect; Build parses into a
+// copy and leaves no havings behind. fields are validated against the
+// 'db' tags of the allowed structs in the same way as Build
+func (b *QueryBuilder) BuildHaving(allowed map[string]interface{}) (having string, namedParamMap map[string]interface{}, err error) {
+	allowed = b.allowedTables(allowed)
+	b.allowed = allowed
+	b.surfaces = b.filterableSurfaces(allowed)
+	namedParamMap = make(map[string]interface{})
+	conditions := []string{}
+	counts := 0
+
+	for i, field := range b.Havings {
+		var expr, namedParam string
+		if field.TableAlias == "" && field.FieldName == "*" {
+			// the group cardinality from AddHavingCount
+			if len(b.GroupBy) == 0 {
+				return "", nil, fmt.Errorf("having: COUNT(*) requires a GROUP BY")
+			}
+			if !field.Operator.IsAggregateSafe() || field.Operator.IsNull() || field.Operator.IsMultiValue() {
+				return "", nil, fmt.Errorf("having: %s cannot be applied to COUNT(*)", field.Operator)
+			}
+			expr = "COUNT(*)"
+			// later counts are suffixed so each bound keeps its param
+			namedParam = "having_count"
+			if counts > 0 {
+				namedParam = fmt.Sprintf("having_count_%d", counts)
+			}
+			namedParam = b.paramName(namedParam)
+			counts++
+		} else {
+			if structField, ok := b.lookupField(allowed, field.TableAlias, field.FieldName); !ok || !fieldAllows(structField, "filter") || !b.isFilterable(field.TableAlias, field.FieldName) {
+				return "", nil, fmt.Errorf("having: %s.%s is not allowed", field.TableAlias, field.FieldName)
+			}
+			if err := b.checkIdent(field.TableAlias, field.FieldName); err != nil {
+				return "", nil, fmt.Errorf("having: %w", err)
+			}
+			expr = fmt.Sprintf("%s(%s)", strings.ToUpper(string(field.Aggregate)), b.column(field.TableAlias, field.FieldName))
+			namedParam = b.paramName(fmt.Sprintf("having_%s_%s_%s_%d", field.Aggregate, field.TableAlias, field.FieldName, i))
+		}
+
+		switch {
+		case field.Operator.IsNull():
+			conditions = append(conditions, fmt.Sprintf("%s %s", expr, field.Operator.Convert()))
+
+		case field.Operator.IsBetween() || field.Operator.IsNotBetween():
+			if len(field.Values) != 2 {
+				return "", nil, fmt.Errorf("having: %s requires two values", field.Operator)
+			}
+			namedParamMap[namedParam+"_0"] = field.Values[0]
+			namedParamMap[namedParam+"_1"] = field.Values[1]
+			conditions = append(conditions, fmt.Sprintf("%s %s :%s_0 AND :%s_1", expr, field.Operator.Convert(), namedParam, namedParam))
+
+		case field.Operator.IsIn() || field.Operator.IsNotIn():
+			var placeholders []string
+			for j, val := range field.Values {
+				name := fmt.Sprintf("%s_%d", namedParam, j)
+				namedParamMap[name] = val
+				placeholders = append(placeholders, ":"+name)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s (%s)", expr, field.Operator.Convert(), strings.Join(placeholders, ", ")))
+
+		default:
+			namedParamMap[namedParam] = field.Value
+			conditions = append(conditions, fmt.Sprintf("%s %s :%s", expr, field.Operator.Convert(), namedParam))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", namedParamMap, nil
+	}
+	return fmt.Sprintf("HAVING %s", strings.Join(conditions, " AND ")), namedParamMap, nil
+}
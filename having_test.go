@@ -0,0 +1,106 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderHaving(t *testing.T) {
+	t.Run("should parse and build a having filter", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		on := "filter=p-name-like-cotton&having=sum-pr-amount-gt-100"
+		allowed := map[string]interface{}{
+			"p":  Product{},
+			"pr": Pricing{},
+		}
+
+		err := builder.ParseParamString(on)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(builder.Havings))
+		assert.Equal(t, buildsql.Sum, builder.Havings[0].Aggregate)
+
+		having, namedParamMap, err := builder.BuildHaving(allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "HAVING SUM(pr.amount) > :having_sum_pr_amount_0", having)
+		assert.Equal(t, "100", namedParamMap["having_sum_pr_amount_0"])
+	})
+
+	t.Run("should leave no havings behind after Build", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		allowed := map[string]interface{}{"pr": Pricing{}}
+
+		_, _, _, err := builder.Build("having=sum-pr-amount-gt-100", allowed)
+		assert.Nil(t, err)
+
+		having, _, err := builder.BuildHaving(allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", having)
+	})
+
+	t.Run("should reject an operator that is not aggregate safe", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString("having=sum-pr-amount-like-10")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject an unknown aggregate", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString("having=median-pr-amount-gt-10")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a field that is not allowed", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.ParseParamString("having=count-pr-missing-gt-1")
+		assert.Nil(t, err)
+
+		_, _, err = builder.BuildHaving(map[string]interface{}{"pr": Pricing{}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderHavingCount(t *testing.T) {
+	t.Run("should emit a having count with a group by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.GroupBy = []string{"p.id"}
+		builder.AddHavingCount(buildsql.GreaterThanOrEqual, 3)
+
+		query, namedParamMap, err := builder.BuildSelect("product p JOIN pricing pr ON pr.product_id = p.id", []string{"p.id", "COUNT(*)"}, "filter=p-name-like-cotton", map[string]interface{}{
+			"p":  Product{},
+			"pr": Pricing{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT p.id, COUNT(*) FROM product p JOIN pricing pr ON pr.product_id = p.id WHERE p.name LIKE :filter_p_name_0 GROUP BY p.id HAVING COUNT(*) >= :having_count", query)
+		assert.Equal(t, 3, namedParamMap["having_count"])
+	})
+
+	t.Run("should bind each bound of a ranged having count", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.GroupBy = []string{"p.id"}
+		builder.AddHavingCount(buildsql.GreaterThanOrEqual, 2).AddHavingCount(buildsql.LessThanOrEqual, 10)
+
+		having, namedParamMap, err := builder.BuildHaving(map[string]interface{}{"p": Product{}})
+		assert.Nil(t, err)
+		assert.Equal(t, "HAVING COUNT(*) >= :having_count AND COUNT(*) <= :having_count_1", having)
+		assert.Equal(t, map[string]interface{}{"having_count": 2, "having_count_1": 10}, namedParamMap)
+	})
+
+	t.Run("should reject a having count without a group by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddHavingCount(buildsql.GreaterThan, 1)
+
+		_, _, err := builder.BuildHaving(map[string]interface{}{"p": Product{}})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a having count with a multi value operator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.GroupBy = []string{"p.id"}
+		builder.AddHavingCount(buildsql.Between, 1)
+
+		_, _, err := builder.BuildHaving(map[string]interface{}{"p": Product{}})
+		assert.NotNil(t, err)
+	})
+}
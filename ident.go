@@ -0,0 +1,30 @@
+package buildsql
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultIdentifierPattern is the pattern every emitted alias and
+// field name must match when IdentifierPattern is nil
+var DefaultIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// paramNamePattern matches the names a ParamNamer may return, the
+// names the named param binding recognizes
+var paramNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// checkIdent rejects aliases and field names that don't match the
+// identifier pattern, a second line of defense behind the allowed
+// fields for a misconfigured whitelist; empty aliases are skipped
+func (b *QueryBuilder) checkIdent(idents ...string) error {
+	pattern := b.IdentifierPattern
+	if pattern == nil {
+		pattern = DefaultIdentifierPattern
+	}
+	for _, ident := range idents {
+		if ident != "" && !pattern.MatchString(ident) {
+			return fmt.Errorf("%q is not a valid identifier", ident)
+		}
+	}
+	return nil
+}
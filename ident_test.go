@@ -0,0 +1,77 @@
+package buildsql_test
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+type Misconfigured struct {
+	ID   int64  `db:"id"`
+	Name string `db:"na me"`
+}
+
+func TestQueryBuilderIdentifierPattern(t *testing.T) {
+	t.Run("should reject an allowed alias with a semicolon", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		allowed := map[string]interface{}{"p;drop": Product{}}
+
+		_, _, _, err := builder.Build("filter=p%3Bdrop-id-eq-1", allowed)
+		assert.NotNil(t, err)
+
+		_, _, _, err = builder.Build("sortOn=p%3Bdrop-id", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject an allowed field with a space", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.Build("filter=m-na%20me-eq-x", map[string]interface{}{"m": Misconfigured{}})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should use a configured pattern", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.IdentifierPattern = regexp.MustCompile(`^[a-z_ ]+$`)
+
+		where, _, _, err := builder.Build("filter=m-na%20me-eq-x", map[string]interface{}{"m": Misconfigured{}})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND m.na me = :filter_m_na me_0", where)
+
+		_, _, _, err = builder.Build("filter=p1-id-eq-1", map[string]interface{}{"p1": Product{}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestParseParamStringIdentifiers(t *testing.T) {
+	t.Run("should reject an injection attempt in a field without a whitelist", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		err := builder.ParseParamString("filter=" + url.QueryEscape("p-name);DROP TABLE users;--eq-x"))
+		assert.EqualError(t, err, `filter[0]: "name);DROP TABLE users;" is not a valid identifier`)
+	})
+
+	t.Run("should reject invalid aliases and fields in every token", func(t *testing.T) {
+		for _, on := range []string{
+			"filter=p%20x-id-eq-1",
+			"filter=p-id%27-in-1,2",
+			"sortOn=-p-name%3B",
+			"having=sum-pr-amount()-gt-1",
+		} {
+			builder := buildsql.NewQueryBuilder()
+			assert.NotNil(t, builder.ParseParamString(on), on)
+
+			_, _, _, err := builder.Build(on, nil)
+			assert.NotNil(t, err, on)
+		}
+	})
+
+	t.Run("should accept safe identifiers", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		assert.Nil(t, builder.ParseParamString("filter=p_1-created_at-gt-1&sortOn=-_p-Name2&having=sum-pr-amount-gt-1"))
+	})
+}
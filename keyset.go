@@ -0,0 +1,182 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegisterTable registers the struct for a table alias together with its
+// primary key, the unique tiebreaker used by Seek for stable paging
+// the primary key must be a 'db' tag on the struct
+func (b *QueryBuilder) RegisterTable(alias string, model interface{}, primaryKey string) error {
+	if _, ok := b.lookupField(map[string]interface{}{alias: model}, alias, primaryKey); !ok {
+		return fmt.Errorf("register: %s is not a field of %s", primaryKey, alias)
+	}
+
+	if b.Tables == nil {
+		b.Tables = make(map[string]interface{})
+	}
+	if b.PrimaryKeys == nil {
+		b.PrimaryKeys = make(map[string]string)
+	}
+	b.Tables[alias] = model
+	b.PrimaryKeys[alias] = primaryKey
+	b.cacheFilterable(model)
+	return nil
+}
+
+// Seek builds a keyset paginated query against the registered tables
+// after holds the sort values of the last row of the previous page keyed
+// by alias.field; leave it empty for the first page
+// the primary key of the first sorted alias is appended to the sort when
+// the client didn't sort on it, so rows with equal sort values page stably
+// example:
+//
+//	builder.RegisterTable("p", Product{}, "id")
+//	where, orderBy, namedParamMap, err := builder.Seek("sortOn=p-name", map[string]interface{}{
+//		"p.name": "cotton gloves",
+//		"p.id":   42,
+//	})
+//	// AND (p.name > :seek_p_name OR (p.name = :seek_p_name AND p.id > :seek_p_id))
+//	// ORDER BY p.name ASC, p.id ASC
+func (b *QueryBuilder) Seek(paramString string, after map[string]interface{}) (where string, orderBy string, namedParamMap map[string]interface{}, err error) {
+	req, err := b.parse(paramString)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return req.keyset(req.Tables, after)
+}
+
+// BuildKeyset builds like Seek against the allowed structs instead of the
+// registered tables; after holds the sort values of the last row keyed by
+// alias.field, and mixed ASC and DESC sorts compare lexicographically
+// without sorts or after values it builds like Build, so the first page
+// needs no special casing
+// example:
+//
+//	where, orderBy, namedParamMap, err := builder.BuildKeyset("sortOn=-p-amount&sortOn=p-id", allowed, map[string]interface{}{
+//		"p.amount": 9.5,
+//		"p.id":     42,
+//	})
+//	// AND (p.amount < :seek_p_amount OR (p.amount = :seek_p_amount AND p.id > :seek_p_id))
+func (b *QueryBuilder) BuildKeyset(paramString string, allowed map[string]interface{}, after map[string]interface{}) (where string, orderBy string, namedParamMap map[string]interface{}, err error) {
+	req, err := b.parse(paramString)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return req.keyset(allowed, after)
+}
+
+// keyset appends the primary key tiebreaker, builds the parsed state and
+// ANDs in the seek predicate over the sorts the build wrote into the
+// order by, so disallowed and duplicate sorts are dropped from both
+func (b *QueryBuilder) keyset(allowed map[string]interface{}, after map[string]interface{}) (where string, orderBy string, namedParamMap map[string]interface{}, err error) {
+	allowed = b.allowedTables(allowed)
+	if err := b.appendPrimaryKeySort(allowed); err != nil {
+		return "", "", nil, err
+	}
+
+	where, orderBy, namedParamMap, err = b.build(allowed)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if len(after) == 0 || len(b.sorted) == 0 {
+		return where, orderBy, namedParamMap, nil
+	}
+
+	predicate, err := b.seekPredicate(after, namedParamMap)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return where + " AND " + predicate, orderBy, namedParamMap, nil
+}
+
+// appendPrimaryKeySort adds the primary key of the first sortable alias
+// unless it's already sorted on; it follows the direction of the last sort
+// relevance, random and positional sorts have no column to seek on and
+// are rejected
+func (b *QueryBuilder) appendPrimaryKeySort(allowed map[string]interface{}) error {
+	b.Sorts = append([]SortField{}, b.effectiveSorts()...)
+
+	alias := ""
+	for _, sort := range b.Sorts {
+		if sort.Position > 0 {
+			return fmt.Errorf("seek: position %d can't be used for keyset pagination", sort.Position)
+		}
+		if sort.TableAlias == "" && (sort.FieldName == RelevanceSort || sort.FieldName == RandomSort) {
+			return fmt.Errorf("seek: %s can't be used for keyset pagination", sort.FieldName)
+		}
+		if structField, ok := b.lookupField(allowed, sort.TableAlias, sort.FieldName); alias == "" && ok && fieldAllows(structField, "sort") {
+			alias = sort.TableAlias
+		}
+	}
+	if alias == "" {
+		return nil
+	}
+
+	primaryKey, ok := b.PrimaryKeys[alias]
+	if !ok {
+		return nil
+	}
+
+	for _, sort := range b.Sorts {
+		if sort.TableAlias == alias && sort.FieldName == primaryKey {
+			return nil
+		}
+	}
+
+	b.Sorts = append(b.Sorts, SortField{
+		TableAlias: alias,
+		FieldName:  primaryKey,
+		Direction:  b.Sorts[len(b.Sorts)-1].Direction,
+	})
+	return nil
+}
+
+// seekPredicate renders the lexicographic comparison of the sort columns
+// against the after values, honoring each sort's direction:
+// (a > :a) OR (a = :a AND b < :b) OR ...
+func (b *QueryBuilder) seekPredicate(after map[string]interface{}, namedParamMap map[string]interface{}) (string, error) {
+	columns := []string{}
+	params := []string{}
+	comparisons := []string{}
+
+	for _, sort := range b.sorted {
+		key := fmt.Sprintf("%s.%s", sort.TableAlias, sort.FieldName)
+		value, ok := after[key]
+		if !ok {
+			return "", fmt.Errorf("seek: missing the after value for %s", key)
+		}
+		if err := b.checkIdent(sort.TableAlias, sort.FieldName); err != nil {
+			return "", fmt.Errorf("seek: %w", err)
+		}
+
+		namedParam := b.paramName(fmt.Sprintf("seek_%s_%s", sort.TableAlias, sort.FieldName))
+		namedParamMap[namedParam] = value
+
+		comparison := ">"
+		if sort.Direction == DESC {
+			comparison = "<"
+		}
+
+		columns = append(columns, b.column(sort.TableAlias, sort.FieldName))
+		params = append(params, ":"+namedParam)
+		comparisons = append(comparisons, comparison)
+	}
+
+	ors := []string{}
+	for i := range columns {
+		ands := []string{}
+		for j := 0; j < i; j++ {
+			ands = append(ands, fmt.Sprintf("%s = %s", columns[j], params[j]))
+		}
+		ands = append(ands, fmt.Sprintf("%s %s %s", columns[i], comparisons[i], params[i]))
+		if len(ands) == 1 {
+			ors = append(ors, ands[0])
+		} else {
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", nil
+}
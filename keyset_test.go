@@ -0,0 +1,150 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderSeek(t *testing.T) {
+	t.Run("should append the primary key to a non unique sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterTable("p", Product{}, "id"))
+
+		where, orderBy, namedParamMap, err := builder.Seek("filter=p-sku-eq-abc&sortOn=-p-name", map[string]interface{}{
+			"p.name": "cotton gloves",
+			"p.id":   42,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.name DESC, p.id DESC", orderBy)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0 AND (p.name < :seek_p_name OR (p.name = :seek_p_name AND p.id < :seek_p_id))", where)
+		assert.Equal(t, "cotton gloves", namedParamMap["seek_p_name"])
+		assert.Equal(t, 42, namedParamMap["seek_p_id"])
+	})
+
+	t.Run("should not append the primary key twice", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterTable("p", Product{}, "id"))
+
+		_, orderBy, _, err := builder.Seek("sortOn=p-id", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC", orderBy)
+	})
+
+	t.Run("should omit the predicate on the first page", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterTable("p", Product{}, "id"))
+
+		where, orderBy, _, err := builder.Seek("sortOn=p-name", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+		assert.Equal(t, "ORDER BY p.name ASC, p.id ASC", orderBy)
+	})
+
+	t.Run("should error on a missing after value", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterTable("p", Product{}, "id"))
+
+		_, _, _, err := builder.Seek("sortOn=p-name", map[string]interface{}{"p.name": "x"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should seek on the sorts written into the order by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterTable("c", Customer{}, "id"))
+
+		where, orderBy, namedParamMap, err := builder.Seek("sortOn=c-email&sortOn=c-nope&sortOn=-c-id&sortOn=c-id", map[string]interface{}{
+			"c.id": 42,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY c.id DESC", orderBy)
+		assert.Equal(t, " AND (c.id < :seek_c_id)", where)
+		assert.Equal(t, map[string]interface{}{"seek_c_id": 42}, namedParamMap)
+	})
+
+	t.Run("should reject sorts without a column to seek on", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AllowRandomSort = true
+		assert.Nil(t, builder.RegisterTable("p", Product{}, "id"))
+
+		_, _, _, err := builder.Seek("sortOn=@random", nil)
+		assert.EqualError(t, err, "seek: @random can't be used for keyset pagination")
+
+		_, _, _, err = builder.Seek("q=gloves&sortOn=-relevance", nil)
+		assert.EqualError(t, err, "seek: relevance can't be used for keyset pagination")
+
+		builder.PositionalOrderBy = true
+		_, _, _, err = builder.Seek("sortOn=2", nil)
+		assert.EqualError(t, err, "seek: position 2 can't be used for keyset pagination")
+	})
+
+	t.Run("should validate the primary key exists", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.RegisterTable("p", Product{}, "uuid")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderBuildKeyset(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should compare mixed directions lexicographically", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, namedParamMap, err := builder.BuildKeyset("filter=p-sku-eq-abc&sortOn=-p-amount&sortOn=p-name&sortOn=p-id", allowed, map[string]interface{}{
+			"p.amount": 9.5,
+			"p.name":   "gloves",
+			"p.id":     42,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0 AND (p.amount < :seek_p_amount OR (p.amount = :seek_p_amount AND p.name > :seek_p_name) OR (p.amount = :seek_p_amount AND p.name = :seek_p_name AND p.id > :seek_p_id))", where)
+		assert.Equal(t, "ORDER BY p.amount DESC, p.name ASC, p.id ASC", orderBy)
+		assert.Equal(t, map[string]interface{}{
+			"filter_p_sku_0": "abc",
+			"seek_p_amount":  9.5,
+			"seek_p_name":    "gloves",
+			"seek_p_id":      42,
+		}, namedParamMap)
+	})
+
+	t.Run("should build the first page without after values", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, _, err := builder.BuildKeyset("filter=p-sku-eq-abc&sortOn=p-id", allowed, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0", where)
+		assert.Equal(t, "ORDER BY p.id ASC", orderBy)
+	})
+
+	t.Run("should ignore the after values without a sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, _, err := builder.BuildKeyset("filter=p-sku-eq-abc", allowed, map[string]interface{}{"p.id": 42})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.sku = :filter_p_sku_0", where)
+		assert.Equal(t, "", orderBy)
+	})
+
+	t.Run("should require an after value per sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.BuildKeyset("sortOn=p-name&sortOn=p-id", allowed, map[string]interface{}{"p.name": "gloves"})
+		assert.EqualError(t, err, "seek: missing the after value for p.id")
+	})
+
+	t.Run("should keep disallowed and unknown sorts out of the seek predicate", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, namedParamMap, err := builder.BuildKeyset("sortOn=c-email&sortOn=c-password&sortOn=c-nope&sortOn=c-id", map[string]interface{}{"c": Customer{}}, map[string]interface{}{
+			"c.email":    "a@b.c",
+			"c.password": "x",
+			"c.nope":     1,
+			"c.id":       42,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY c.id ASC", orderBy)
+		assert.Equal(t, " AND (c.id > :seek_c_id)", where)
+		assert.Equal(t, map[string]interface{}{"seek_c_id": 42}, namedParamMap)
+	})
+}
@@ -0,0 +1,66 @@
+package buildsql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RejectedFilter is a client filter BuildLenient skipped
+type RejectedFilter struct {
+	// Index is the position of the filter in the parsed filters
+	Index  int
+	Filter FilterField
+	Reason string
+}
+
+// BuildLenient builds like Build but reports the filters it skipped
+// because their alias or field isn't allowed, so a tolerant api can
+// return them as warnings; filters on fields tagged `buildsql:"-"`
+// still fail the build
+// the rejected filters are returned instead of stored in a Rejected
+// field, the build runs on a request local copy so a shared builder
+// has no per request state to read them from
+// example:
+//
+//	where, orderBy, namedParamMap, rejected, err := builder.BuildLenient(on, allowed)
+//	for _, r := range rejected {
+//		log.Printf("ignored filter[%d]: %s", r.Index, r.Reason)
+//	}
+func (b *QueryBuilder) BuildLenient(paramString string, allowed map[string]interface{}) (where string, orderBy string, namedParamMap map[string]interface{}, rejected []RejectedFilter, err error) {
+	req, err := b.parse(paramString)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+	req.lenient = true
+
+	where, orderBy, namedParamMap, err = req.build(allowed)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+	return where, orderBy, namedParamMap, req.rejected, nil
+}
+
+// reject records the filter when build is going to skip it
+// it errors for fields that are hard disallowed by their tag
+func (b *QueryBuilder) reject(index int, field FilterField, allowed map[string]interface{}, resolve func(FilterField) (reflect.Type, bool)) error {
+	if _, ok := resolve(field); ok {
+		return nil
+	}
+
+	reason := fmt.Sprintf("%s.%s is not an allowed field", field.TableAlias, field.FieldName)
+	if _, ok := allowed[field.TableAlias]; !ok {
+		reason = fmt.Sprintf("%s is not an allowed alias", field.TableAlias)
+	} else if structField, ok := b.lookupField(allowed, field.TableAlias, field.FieldName); ok {
+		if structField.Tag.Get("buildsql") == "-" {
+			return fmt.Errorf("filter[%d] %s.%s: field is not allowed", index, field.TableAlias, field.FieldName)
+		}
+		reason = fmt.Sprintf("%s.%s can't be filtered on", field.TableAlias, field.FieldName)
+	}
+
+	b.rejected = append(b.rejected, RejectedFilter{
+		Index:  index,
+		Filter: field,
+		Reason: reason,
+	})
+	return nil
+}
@@ -0,0 +1,44 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderLenient(t *testing.T) {
+	allowed := map[string]interface{}{"c": Customer{}}
+
+	t.Run("should collect the rejected filters and build the valid ones", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, rejected, err := builder.BuildLenient("filter=x-id-eq-1&filter=c-email-eq-a@b.c&filter=c-nickname-eq-bob&filter=c-notes-eq-vip", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND c.email = :filter_c_email_0", where)
+		assert.Equal(t, "a@b.c", namedParamMap["filter_c_email_0"])
+
+		assert.Equal(t, 3, len(rejected))
+		assert.Equal(t, 0, rejected[0].Index)
+		assert.Equal(t, "x is not an allowed alias", rejected[0].Reason)
+		assert.Equal(t, 2, rejected[1].Index)
+		assert.Equal(t, "nickname", rejected[1].Filter.FieldName)
+		assert.Equal(t, "c.nickname is not an allowed field", rejected[1].Reason)
+		assert.Equal(t, "c.notes can't be filtered on", rejected[2].Reason)
+	})
+
+	t.Run("should error on a hard disallowed field", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, _, err := builder.BuildLenient("filter=c-password-eq-x", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should return no rejected filters when all are valid", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, rejected, err := builder.BuildLenient("filter=c-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Nil(t, rejected)
+	})
+}
@@ -0,0 +1,74 @@
+package buildsql
+
+import "fmt"
+
+// LockStrength is the row lock a select takes
+type LockStrength string
+
+const (
+	ForUpdate LockStrength = "UPDATE"
+	ForShare  LockStrength = "SHARE"
+)
+
+// Locking configures the locking clause of BuildSelect
+type Locking struct {
+	Strength LockStrength
+
+	// SkipLocked skips the rows locked by other transactions
+	SkipLocked bool
+
+	// NoWait errors instead of waiting for locked rows
+	NoWait bool
+}
+
+// LockingDialect is implemented by dialects supporting row locking
+type LockingDialect interface {
+	// LockingClause returns the clause following the order by and limit
+	LockingClause(locking Locking) string
+}
+
+func (postgres) LockingClause(locking Locking) string {
+	return standardLockingClause(locking)
+}
+
+func (mysql) LockingClause(locking Locking) string {
+	return standardLockingClause(locking)
+}
+
+func standardLockingClause(locking Locking) string {
+	clause := "FOR " + string(locking.Strength)
+	switch {
+	case locking.SkipLocked:
+		clause += " SKIP LOCKED"
+	case locking.NoWait:
+		clause += " NOWAIT"
+	}
+	return clause
+}
+
+// lockingClause validates Locking and renders it through the dialect
+// without a dialect the postgres form is used
+func (b *QueryBuilder) lockingClause() (string, error) {
+	locking := b.Locking
+	if locking.Strength == "" {
+		if locking.SkipLocked || locking.NoWait {
+			return "", fmt.Errorf("locking: SkipLocked and NoWait require a strength")
+		}
+		return "", nil
+	}
+	if locking.Strength != ForUpdate && locking.Strength != ForShare {
+		return "", fmt.Errorf("locking: %s is not a valid strength", locking.Strength)
+	}
+	if locking.SkipLocked && locking.NoWait {
+		return "", fmt.Errorf("locking: SkipLocked and NoWait can't be combined")
+	}
+
+	if b.Dialect == nil {
+		return postgres{}.LockingClause(locking), nil
+	}
+	dialect, ok := b.Dialect.(LockingDialect)
+	if !ok {
+		return "", fmt.Errorf("locking: the dialect doesn't support row locking")
+	}
+	return dialect.LockingClause(locking), nil
+}
@@ -0,0 +1,32 @@
+package buildsql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// snakeCase converts a go field name to its snake_case column name
+// an underscore goes before each upper case letter following a lower
+// case letter or digit, and before the last letter of an acronym that
+// starts a new word; digits stick to the word before them
+//
+//	FirstName => first_name
+//	ID        => id
+//	UserID    => user_id
+//	HTTPCode  => http_code
+//	Address2  => address2
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package buildsql
+
+import "fmt"
+
+// NullsOrder places the NULLs of a sorted column
+type NullsOrder string
+
+const (
+	NullsFirst NullsOrder = "nullsfirst"
+	NullsLast  NullsOrder = "nullslast"
+)
+
+// NullsDialect is implemented by dialects without the standard
+// NULLS FIRST and NULLS LAST modifiers
+type NullsDialect interface {
+	// OrderNulls returns the ORDER BY terms sorting the column in the
+	// direction with its NULLs placed first or last
+	OrderNulls(column string, dir SortDirection, nulls NullsOrder) string
+}
+
+// OrderNulls sorts on column IS NULL first, which is 1 for the NULLs
+func (mysql) OrderNulls(column string, dir SortDirection, nulls NullsOrder) string {
+	nullsDir := ASC
+	if nulls == NullsFirst {
+		nullsDir = DESC
+	}
+	return fmt.Sprintf("%s IS NULL %s, %s %s", column, nullsDir, column, dir)
+}
+
+// OrderNulls sorts on a CASE ranking the NULLs first, as sql server
+// has no boolean expressions to order by
+func (sqlServer) OrderNulls(column string, dir SortDirection, nulls NullsOrder) string {
+	first, rest := 1, 0
+	if nulls == NullsFirst {
+		first, rest = 0, 1
+	}
+	return fmt.Sprintf("CASE WHEN %s IS NULL THEN %d ELSE %d END, %s %s", column, first, rest, column, dir)
+}
+
+// orderNulls renders the sort term with its nulls modifier through the
+// dialect; without one, or for dialects supporting the standard
+// modifiers, NULLS FIRST or NULLS LAST is appended
+func orderNulls(dialect Dialect, column string, dir SortDirection, nulls NullsOrder) string {
+	if nulls == "" {
+		return fmt.Sprintf("%s %s", column, dir)
+	}
+	if dialect, ok := dialect.(NullsDialect); ok {
+		return dialect.OrderNulls(column, dir, nulls)
+	}
+	if nulls == NullsFirst {
+		return fmt.Sprintf("%s %s NULLS FIRST", column, dir)
+	}
+	return fmt.Sprintf("%s %s NULLS LAST", column, dir)
+}
@@ -0,0 +1,39 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildOrderByNulls(t *testing.T) {
+	allowed := map[string]string{"name": "p", "amount": "pr", "id": "p"}
+
+	t.Run("should append the standard nulls modifiers", func(t *testing.T) {
+		orderBy, err := buildsql.BuildOrderBy("-amount:nullslast, name:nullsfirst, id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY pr.amount DESC NULLS LAST, p.name ASC NULLS FIRST, p.id ASC", orderBy)
+
+		orderBy, err = buildsql.BuildOrderBy("-amount:nullslast,name", allowed, buildsql.Postgres)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY pr.amount DESC NULLS LAST, p.name ASC", orderBy)
+	})
+
+	t.Run("should emulate the modifiers on mysql", func(t *testing.T) {
+		orderBy, err := buildsql.BuildOrderBy("-amount:nullslast,name:nullsfirst,id", allowed, buildsql.MySQL)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY pr.amount IS NULL ASC, pr.amount DESC, p.name IS NULL DESC, p.name ASC, p.id ASC", orderBy)
+	})
+
+	t.Run("should emulate the modifiers on sql server", func(t *testing.T) {
+		orderBy, err := buildsql.BuildOrderBy("-amount:nullslast,name:nullsfirst", allowed, buildsql.SQLServer)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY CASE WHEN pr.amount IS NULL THEN 1 ELSE 0 END, pr.amount DESC, CASE WHEN p.name IS NULL THEN 0 ELSE 1 END, p.name ASC", orderBy)
+	})
+
+	t.Run("should reject an unknown modifier", func(t *testing.T) {
+		_, err := buildsql.BuildOrderBy("name:nullsmiddle", allowed)
+		assert.NotNil(t, err)
+	})
+}
@@ -1,11 +1,19 @@
 package buildsql
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Operator string
 
 const (
 	Equal              Operator = "eq"
+	IEqual             Operator = "ieq"
 	NotEqual           Operator = "neq"
 	Like               Operator = "like"
+	LikeStart          Operator = "likestart"
+	LikeEnd            Operator = "likeend"
 	ILike              Operator = "ilike"
 	OrLike             Operator = "orlike"
 	OrILike            Operator = "orilike"
@@ -16,20 +24,41 @@ const (
 	GreaterThan        Operator = "gt"
 	GreaterThanOrEqual Operator = "gte"
 	Between            Operator = "btw"
+	NotBetween         Operator = "nbtw"
 	Or                 Operator = "or"
 	In                 Operator = "in"
 	NotIn              Operator = "notin"
 	IsNull             Operator = "isnull"
 	IsNotNull          Operator = "isnotnull"
+	AnyEqual           Operator = "anyeq"
+	Contains           Operator = "contains"
+	NotContains        Operator = "ncontains"
+	Empty              Operator = "empty"
+	NotEmpty           Operator = "nempty"
+	IsTrue             Operator = "istrue"
+	IsFalse            Operator = "isfalse"
+	IsNotTrue          Operator = "isnottrue"
+	IsNotFalse         Operator = "isnotfalse"
+	Similar            Operator = "simil"
+	SimilarAbove       Operator = "similth"
 )
 
+// operators lists every known operator, in declaration order
+var operators = []Operator{
+	Equal, IEqual, NotEqual, Like, LikeStart, LikeEnd, ILike, OrLike, OrILike, NotLike, NotILike,
+	LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual,
+	Between, NotBetween, Or, In, NotIn, IsNull, IsNotNull, AnyEqual, Contains, NotContains,
+	Empty, NotEmpty, IsTrue, IsFalse, IsNotTrue, IsNotFalse,
+	Similar, SimilarAbove,
+}
+
 func (o Operator) Convert() string {
 	switch o {
-	case Equal:
+	case Equal, IEqual:
 		return "="
 	case NotEqual:
 		return "!="
-	case Like:
+	case Like, LikeStart, LikeEnd:
 		return "LIKE"
 	case ILike:
 		return "ILIKE"
@@ -51,6 +80,8 @@ func (o Operator) Convert() string {
 		return ">="
 	case Between:
 		return "BETWEEN"
+	case NotBetween:
+		return "NOT BETWEEN"
 	case In:
 		return "IN"
 	case NotIn:
@@ -59,18 +90,85 @@ func (o Operator) Convert() string {
 		return "IS NULL"
 	case IsNotNull:
 		return "IS NOT NULL"
+	case AnyEqual:
+		return "= ANY"
+	case Contains:
+		return "@>"
+	case NotContains:
+		return "NOT @>"
+	case Empty:
+		return "= ''"
+	case NotEmpty:
+		return "!= ''"
+	case IsTrue:
+		return "IS TRUE"
+	case IsFalse:
+		return "IS FALSE"
+	case IsNotTrue:
+		return "IS NOT TRUE"
+	case IsNotFalse:
+		return "IS NOT FALSE"
+	case Similar:
+		return "%"
+	case SimilarAbove:
+		return "similarity >"
 	}
 	return ""
 }
 
+// IsValid reports whether the operator is a known operator
+func (o Operator) IsValid() bool {
+	switch o {
+	case Equal, IEqual, NotEqual, Like, LikeStart, LikeEnd, ILike, OrLike, OrILike, NotLike, NotILike,
+		LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual,
+		Between, NotBetween, Or, In, NotIn, IsNull, IsNotNull, AnyEqual, Contains, NotContains,
+		Empty, NotEmpty, IsTrue, IsFalse, IsNotTrue, IsNotFalse,
+		Similar, SimilarAbove:
+		return true
+	}
+	return false
+}
+
 func (o Operator) IsLike() bool {
-	return (o == Like || o == OrLike || o == ILike || o == OrILike) || (o == NotLike || o == NotILike)
+	return (o == Like || o == OrLike || o == ILike || o == OrILike) || (o == NotLike || o == NotILike) || (o == LikeStart || o == LikeEnd)
+}
+
+// pattern wraps the value in the % wildcards of the like operator,
+// likestart matches a prefix and likeend a suffix, the others contain it
+func (o Operator) pattern(value interface{}) string {
+	switch o {
+	case LikeStart:
+		return fmt.Sprintf("%v%%", value)
+	case LikeEnd:
+		return fmt.Sprintf("%%%v", value)
+	}
+	return fmt.Sprintf("%%%v%%", value)
+}
+
+// unpattern strips the wildcards added by pattern
+func (o Operator) unpattern(value string) string {
+	switch o {
+	case LikeStart:
+		return strings.TrimSuffix(value, "%")
+	case LikeEnd:
+		return strings.TrimPrefix(value, "%")
+	}
+	if len(value) >= 2 && strings.HasPrefix(value, "%") && strings.HasSuffix(value, "%") {
+		return value[1 : len(value)-1]
+	}
+	return value
 }
 
 func (o Operator) IsBetween() bool {
 	return o == Between
 }
 
+// IsNotBetween reports whether the operator excludes a range,
+// it takes two values like btw
+func (o Operator) IsNotBetween() bool {
+	return o == NotBetween
+}
+
 func (o Operator) IsIn() bool {
 	return o == In
 }
@@ -79,10 +177,66 @@ func (o Operator) IsNotIn() bool {
 	return o == NotIn
 }
 
+func (o Operator) IsContains() bool {
+	return o == Contains || o == NotContains
+}
+
+// IsSimilarity reports whether the operator is a pg_trgm trigram
+// similarity match; similth takes the term and the threshold
+func (o Operator) IsSimilarity() bool {
+	return o == Similar || o == SimilarAbove
+}
+
+// IsMultiValue reports whether the operator takes a comma separated value list
+func (o Operator) IsMultiValue() bool {
+	return o.IsBetween() || o.IsNotBetween() || o.IsIn() || o.IsNotIn() || o.IsContains() || o == SimilarAbove
+}
+
 func (o Operator) IsNull() bool {
 	return o == IsNull || o == IsNotNull
 }
 
+// IsEmpty reports whether the operator matches blank strings,
+// NULL or the empty string; like IsNull it takes no value
+func (o Operator) IsEmpty() bool {
+	return o == Empty || o == NotEmpty
+}
+
+// IsTruth reports whether the operator is one of the IS [NOT] TRUE/FALSE
+// tests of a bool column, which unlike = tell NULL apart
+func (o Operator) IsTruth() bool {
+	return o == IsTrue || o == IsFalse || o == IsNotTrue || o == IsNotFalse
+}
+
+// IsNullary reports whether the operator takes no value
+func (o Operator) IsNullary() bool {
+	return o.IsNull() || o.IsEmpty() || o.IsTruth()
+}
+
+// IsAggregateSafe reports whether the operator can be applied to an
+// aggregate in a HAVING clause; pattern matching makes no sense there
+func (o Operator) IsAggregateSafe() bool {
+	switch o {
+	case Equal, NotEqual, LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual,
+		Between, NotBetween, In, NotIn, IsNull, IsNotNull:
+		return true
+	}
+	return false
+}
+
+// cost ranks the operator for CheapPredicatesFirst: equality and
+// membership checks are cheapest, then ranges, then pattern matches
+// and anything unknown, like the full text search
+func (o Operator) cost() int {
+	switch o {
+	case Equal, IEqual, In, IsNull, IsNotNull, AnyEqual, Empty, IsTrue, IsFalse:
+		return 0
+	case NotEqual, NotIn, NotEmpty, IsNotTrue, IsNotFalse, LessThan, LessThanOrEqual, GreaterThan, GreaterThanOrEqual, Between, NotBetween:
+		return 1
+	}
+	return 2
+}
+
 // to string
 func (o Operator) String() string {
 	return string(o)
@@ -10,8 +10,11 @@ import (
 func TestOperator(t *testing.T) {
 	t.Run("Convert should return correct SQL representation", func(t *testing.T) {
 		assert.Equal(t, "=", buildsql.Equal.Convert())
+		assert.Equal(t, "=", buildsql.IEqual.Convert())
 		assert.Equal(t, "!=", buildsql.NotEqual.Convert())
 		assert.Equal(t, "LIKE", buildsql.Like.Convert())
+		assert.Equal(t, "LIKE", buildsql.LikeStart.Convert())
+		assert.Equal(t, "LIKE", buildsql.LikeEnd.Convert())
 		assert.Equal(t, "ILIKE", buildsql.ILike.Convert())
 		assert.Equal(t, "LIKE", buildsql.OrLike.Convert())
 		assert.Equal(t, "ILIKE", buildsql.OrILike.Convert())
@@ -22,14 +25,26 @@ func TestOperator(t *testing.T) {
 		assert.Equal(t, ">", buildsql.GreaterThan.Convert())
 		assert.Equal(t, ">=", buildsql.GreaterThanOrEqual.Convert())
 		assert.Equal(t, "BETWEEN", buildsql.Between.Convert())
+		assert.Equal(t, "NOT BETWEEN", buildsql.NotBetween.Convert())
 		assert.Equal(t, "IN", buildsql.In.Convert())
 		assert.Equal(t, "NOT IN", buildsql.NotIn.Convert())
 		assert.Equal(t, "IS NULL", buildsql.IsNull.Convert())
 		assert.Equal(t, "IS NOT NULL", buildsql.IsNotNull.Convert())
+		assert.Equal(t, "= ANY", buildsql.AnyEqual.Convert())
+		assert.Equal(t, "@>", buildsql.Contains.Convert())
+		assert.Equal(t, "NOT @>", buildsql.NotContains.Convert())
+		assert.Equal(t, "= ''", buildsql.Empty.Convert())
+		assert.Equal(t, "!= ''", buildsql.NotEmpty.Convert())
+		assert.Equal(t, "IS TRUE", buildsql.IsTrue.Convert())
+		assert.Equal(t, "IS FALSE", buildsql.IsFalse.Convert())
+		assert.Equal(t, "IS NOT TRUE", buildsql.IsNotTrue.Convert())
+		assert.Equal(t, "IS NOT FALSE", buildsql.IsNotFalse.Convert())
 	})
 
 	t.Run("IsLike should return true for like operators", func(t *testing.T) {
 		assert.True(t, buildsql.Like.IsLike())
+		assert.True(t, buildsql.LikeStart.IsLike())
+		assert.True(t, buildsql.LikeEnd.IsLike())
 		assert.True(t, buildsql.OrLike.IsLike())
 		assert.True(t, buildsql.ILike.IsLike())
 		assert.True(t, buildsql.OrILike.IsLike())
@@ -44,4 +59,27 @@ func TestOperator(t *testing.T) {
 		assert.False(t, buildsql.GreaterThan.IsLike())
 		assert.False(t, buildsql.Between.IsLike())
 	})
+
+	t.Run("IsNotBetween should only be true for nbtw", func(t *testing.T) {
+		assert.True(t, buildsql.NotBetween.IsNotBetween())
+		assert.True(t, buildsql.NotBetween.IsMultiValue())
+		assert.False(t, buildsql.NotBetween.IsBetween())
+		assert.False(t, buildsql.Between.IsNotBetween())
+	})
+}
+
+func TestOperatorIsAggregateSafe(t *testing.T) {
+	t.Run("comparison operators are aggregate safe", func(t *testing.T) {
+		assert.True(t, buildsql.Equal.IsAggregateSafe())
+		assert.True(t, buildsql.GreaterThanOrEqual.IsAggregateSafe())
+		assert.True(t, buildsql.Between.IsAggregateSafe())
+		assert.True(t, buildsql.In.IsAggregateSafe())
+	})
+
+	t.Run("pattern operators are not aggregate safe", func(t *testing.T) {
+		assert.False(t, buildsql.Like.IsAggregateSafe())
+		assert.False(t, buildsql.NotILike.IsAggregateSafe())
+		assert.False(t, buildsql.OrLike.IsAggregateSafe())
+		assert.False(t, buildsql.Or.IsAggregateSafe())
+	})
 }
@@ -0,0 +1,184 @@
+package buildsql
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Paginator holds a page's limit and offset, independent of filtering
+// a zero Limit means no limit
+type Paginator struct {
+	Limit  int
+	Offset int
+
+	// Bind renders the limit and offset as :limit and :offset named
+	// params instead of inline numbers, so every page shares one
+	// prepared statement; the values come from Params. both are always
+	// bound, so set a Limit or Clamp it: a zero limit binds LIMIT 0
+	Bind bool
+}
+
+// FromQuery reads the limit and offset query params
+// missing params leave the current values untouched
+func (p *Paginator) FromQuery(q url.Values) error {
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{"limit", &p.Limit},
+		{"offset", &p.Offset},
+	} {
+		raw := strings.TrimSpace(q.Get(param.name))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s: %q is not a valid non-negative integer", param.name, raw)
+		}
+		*param.value = n
+	}
+	return nil
+}
+
+// DefaultMaxLimit caps the parsed limit when MaxLimit is 0
+const DefaultMaxLimit = 1000
+
+// LimitOffset renders the clause of the parsed limit and offset for
+// the Dialect, capped like Build caps them; empty when neither was sent
+// Build already appends it to the order by
+// example:
+//
+//	builder.ParseParamString("limit=20&offset=40")
+//	builder.LimitOffset() // LIMIT 20 OFFSET 40
+func (b *QueryBuilder) LimitOffset() string {
+	if b.Limit == nil && b.Offset == nil {
+		return ""
+	}
+	page := b.parsedPage()
+	return page.Clause(b.Dialect)
+}
+
+// parsedPage returns the parsed limit and offset as a paginator
+// capped at MaxLimit, or DefaultMaxLimit when MaxLimit is 0
+func (b *QueryBuilder) parsedPage() Paginator {
+	var page Paginator
+	if b.Limit != nil {
+		page.Limit = *b.Limit
+	}
+	if b.Offset != nil {
+		page.Offset = *b.Offset
+	}
+	max := b.MaxLimit
+	if max == 0 {
+		max = DefaultMaxLimit
+	}
+	page.Clamp(max)
+	return page
+}
+
+// Clamp caps the limit at max; an unset limit becomes max
+func (p *Paginator) Clamp(max int) *Paginator {
+	if max > 0 && (p.Limit == 0 || p.Limit > max) {
+		p.Limit = max
+	}
+	return p
+}
+
+// PaginationDialect is implemented by dialects that don't use the
+// standard LIMIT n OFFSET m clause
+type PaginationDialect interface {
+	// LimitOffset returns the clause for the limit and offset, which
+	// are numbers or placeholders; an empty limit means no limit
+	LimitOffset(limit, offset string) string
+}
+
+// LimitOffset needs a LIMIT for an OFFSET, so the max row count stands in
+func (mysql) LimitOffset(limit, offset string) string {
+	if limit == "" && offset != "" {
+		limit = "18446744073709551615"
+	}
+	return limitOffset(limit, offset)
+}
+
+// LimitOffset needs a LIMIT for an OFFSET, so -1 stands in for no limit
+func (sqlite) LimitOffset(limit, offset string) string {
+	if limit == "" && offset != "" {
+		limit = "-1"
+	}
+	return limitOffset(limit, offset)
+}
+
+// LimitOffset renders OFFSET FETCH, which requires an ORDER BY
+func (sqlServer) LimitOffset(limit, offset string) string {
+	if limit == "" && offset == "" {
+		return ""
+	}
+	if offset == "" {
+		offset = "0"
+	}
+	clause := fmt.Sprintf("OFFSET %s ROWS", offset)
+	if limit != "" {
+		clause += fmt.Sprintf(" FETCH NEXT %s ROWS ONLY", limit)
+	}
+	return clause
+}
+
+// Clause renders the limit and offset for the dialect
+// without a dialect, or for one that doesn't implement
+// PaginationDialect, the standard LIMIT n OFFSET m is used
+func (p *Paginator) Clause(dialect Dialect) string {
+	return p.clause(dialect, "limit", "offset")
+}
+
+// clause renders the limit and offset, bound to the named params
+func (p *Paginator) clause(dialect Dialect, limitName, offsetName string) string {
+	limit, offset := "", ""
+	switch {
+	case p.Bind:
+		// every page renders the same clause, whatever its values
+		limit, offset = ":"+limitName, ":"+offsetName
+	default:
+		if p.Limit > 0 {
+			limit = strconv.Itoa(p.Limit)
+		}
+		if p.Offset > 0 {
+			offset = strconv.Itoa(p.Offset)
+		}
+	}
+
+	if dialect, ok := dialect.(PaginationDialect); ok {
+		return dialect.LimitOffset(limit, offset)
+	}
+	return limitOffset(limit, offset)
+}
+
+// Params returns the limit and offset named params of a bound clause
+func (p *Paginator) Params() map[string]interface{} {
+	return p.params("limit", "offset")
+}
+
+// params returns the bound limit and offset under the named params
+func (p *Paginator) params(limitName, offsetName string) map[string]interface{} {
+	params := make(map[string]interface{})
+	if !p.Bind {
+		return params
+	}
+	params[limitName] = p.Limit
+	params[offsetName] = p.Offset
+	return params
+}
+
+// limitOffset renders LIMIT n OFFSET m, leaving out the empty parts
+func limitOffset(limit, offset string) string {
+	parts := []string{}
+	if limit != "" {
+		parts = append(parts, "LIMIT "+limit)
+	}
+	if offset != "" {
+		parts = append(parts, "OFFSET "+offset)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,203 @@
+package buildsql_test
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginator(t *testing.T) {
+	t.Run("should read the limit and offset", func(t *testing.T) {
+		var paginator buildsql.Paginator
+		err := paginator.FromQuery(url.Values{"limit": {"20"}, "offset": {"40"}})
+		assert.Nil(t, err)
+		assert.Equal(t, 20, paginator.Limit)
+		assert.Equal(t, 40, paginator.Offset)
+	})
+
+	t.Run("should reject invalid values", func(t *testing.T) {
+		var paginator buildsql.Paginator
+		assert.NotNil(t, paginator.FromQuery(url.Values{"limit": {"abc"}}))
+		assert.NotNil(t, paginator.FromQuery(url.Values{"offset": {"-1"}}))
+	})
+
+	t.Run("should clamp the limit", func(t *testing.T) {
+		paginator := buildsql.Paginator{Limit: 1000000}
+		assert.Equal(t, 100, paginator.Clamp(100).Limit)
+
+		paginator = buildsql.Paginator{Limit: 20}
+		assert.Equal(t, 20, paginator.Clamp(100).Limit)
+
+		paginator = buildsql.Paginator{}
+		assert.Equal(t, 100, paginator.Clamp(100).Limit)
+	})
+
+	for _, tc := range []struct {
+		name      string
+		dialect   buildsql.Dialect
+		paginator buildsql.Paginator
+		clause    string
+	}{
+		{"no dialect", nil, buildsql.Paginator{Limit: 20, Offset: 40}, "LIMIT 20 OFFSET 40"},
+		{"postgres", buildsql.Postgres, buildsql.Paginator{Limit: 20, Offset: 40}, "LIMIT 20 OFFSET 40"},
+		{"postgres offset only", buildsql.Postgres, buildsql.Paginator{Offset: 40}, "OFFSET 40"},
+		{"mysql", buildsql.MySQL, buildsql.Paginator{Limit: 20}, "LIMIT 20"},
+		{"mysql offset only", buildsql.MySQL, buildsql.Paginator{Offset: 40}, "LIMIT 18446744073709551615 OFFSET 40"},
+		{"sqlite offset only", buildsql.SQLite, buildsql.Paginator{Offset: 40}, "LIMIT -1 OFFSET 40"},
+		{"sqlserver", buildsql.SQLServer, buildsql.Paginator{Limit: 20, Offset: 40}, "OFFSET 40 ROWS FETCH NEXT 20 ROWS ONLY"},
+		{"sqlserver limit only", buildsql.SQLServer, buildsql.Paginator{Limit: 20}, "OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY"},
+		{"empty", buildsql.SQLServer, buildsql.Paginator{}, ""},
+	} {
+		t.Run("should render the clause for "+tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.clause, tc.paginator.Clause(tc.dialect))
+		})
+	}
+
+	t.Run("should append the clause to the order by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		paginator := &buildsql.Paginator{Limit: 20, Offset: 40}
+
+		_, orderBy, _, err := builder.Build("sortOn=p-id", map[string]interface{}{"p": Product{}}, paginator)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT 20 OFFSET 40", orderBy)
+	})
+
+	t.Run("should bind the limit and offset as named params", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		paginator := &buildsql.Paginator{Limit: 20, Offset: 40, Bind: true}
+
+		_, orderBy, namedParamMap, err := builder.Build("filter=p-id-gt-1&sortOn=p-id", map[string]interface{}{"p": Product{}}, paginator)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT :limit OFFSET :offset", orderBy)
+		assert.Equal(t, 20, namedParamMap["limit"])
+		assert.Equal(t, 40, namedParamMap["offset"])
+		assert.Equal(t, "1", namedParamMap["filter_p_id_0"])
+	})
+
+	t.Run("should bind through the dialect clause", func(t *testing.T) {
+		paginator := buildsql.Paginator{Limit: 20, Offset: 40, Bind: true}
+		assert.Equal(t, "OFFSET :offset ROWS FETCH NEXT :limit ROWS ONLY", paginator.Clause(buildsql.SQLServer))
+		assert.Equal(t, "LIMIT :limit OFFSET :offset", paginator.Clause(buildsql.MySQL))
+		assert.Equal(t, map[string]interface{}{"limit": 20, "offset": 40}, paginator.Params())
+	})
+
+	t.Run("should render the same bound clause for every page", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		allowed := map[string]interface{}{"p": Product{}}
+
+		_, first, firstParams, err := builder.Build("sortOn=p-id", allowed, &buildsql.Paginator{Limit: 20, Offset: 0, Bind: true})
+		assert.Nil(t, err)
+		_, second, secondParams, err := builder.Build("sortOn=p-id", allowed, &buildsql.Paginator{Limit: 20, Offset: 20, Bind: true})
+		assert.Nil(t, err)
+
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT :limit OFFSET :offset", first)
+		assert.Equal(t, first, second)
+		assert.Equal(t, map[string]interface{}{"limit": 20, "offset": 0}, firstParams)
+		assert.Equal(t, map[string]interface{}{"limit": 20, "offset": 20}, secondParams)
+	})
+
+	t.Run("should inline by default", func(t *testing.T) {
+		paginator := buildsql.Paginator{Limit: 20}
+		assert.Equal(t, "LIMIT 20", paginator.Clause(nil))
+		assert.Equal(t, map[string]interface{}{}, paginator.Params())
+	})
+}
+
+func TestQueryBuilderLimitOffset(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should parse the limit and offset params", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("filter=p-id-gt-1&limit=20&offset=40"))
+		assert.Equal(t, 20, *builder.Limit)
+		assert.Equal(t, 40, *builder.Offset)
+		assert.Equal(t, "LIMIT 20 OFFSET 40", builder.LimitOffset())
+	})
+
+	t.Run("should leave them nil when absent", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.ParseParamString("filter=p-id-gt-1"))
+		assert.Nil(t, builder.Limit)
+		assert.Nil(t, builder.Offset)
+		assert.Equal(t, "", builder.LimitOffset())
+	})
+
+	t.Run("should append them to the order by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, orderBy, _, err := builder.Build("sortOn=p-id&limit=20&offset=40", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT 20 OFFSET 40", orderBy)
+		assert.Nil(t, builder.Limit)
+	})
+
+	t.Run("should prefer a passed paginator", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, orderBy, _, err := builder.Build("sortOn=p-id&limit=20", allowed, &buildsql.Paginator{Limit: 5})
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT 5", orderBy)
+	})
+
+	t.Run("should cap the limit", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		_, orderBy, _, err := builder.Build("sortOn=p-id&limit=1000000", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT "+strconv.Itoa(buildsql.DefaultMaxLimit), orderBy)
+
+		builder.MaxLimit = 50
+		_, orderBy, _, err = builder.Build("sortOn=p-id&offset=10", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT 50 OFFSET 10", orderBy)
+	})
+
+	t.Run("should reject invalid values", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		for _, on := range []string{"limit=abc", "limit=-1", "offset=1.5"} {
+			_, _, _, err := builder.Build(on, allowed)
+			assert.NotNil(t, err, on)
+		}
+	})
+}
+
+func TestQueryBuilderRequireSortWithLimit(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should error on a limit without a sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.RequireSortWithLimit = true
+
+		_, _, _, err := builder.Build("filter=p-id-gt-1&limit=20", allowed)
+		assert.EqualError(t, err, "pagination: a limit or offset requires a sort")
+
+		_, _, _, err = builder.Build("filter=p-id-gt-1", allowed, &buildsql.Paginator{Offset: 20})
+		assert.EqualError(t, err, "pagination: a limit or offset requires a sort")
+
+		_, _, err = builder.BuildSelect("product p", []string{"p.id"}, "limit=20", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should accept a default sort", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.RequireSortWithLimit = true
+		builder.DefaultSort = []buildsql.SortField{{TableAlias: "p", FieldName: "id", Direction: buildsql.ASC}}
+
+		_, orderBy, _, err := builder.Build("filter=p-id-gt-1&limit=20", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id ASC LIMIT 20", orderBy)
+	})
+
+	t.Run("should accept a client sort or no pagination", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.RequireSortWithLimit = true
+
+		_, orderBy, _, err := builder.Build("sortOn=-p-id&limit=20", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "ORDER BY p.id DESC LIMIT 20", orderBy)
+
+		_, orderBy, _, err = builder.Build("filter=p-id-gt-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "", orderBy)
+	})
+}
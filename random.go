@@ -0,0 +1,42 @@
+package buildsql
+
+import "fmt"
+
+// RandomSort is the sort token ordering the rows randomly,
+// only parsed when AllowRandomSort is set
+const RandomSort = "@random"
+
+// RandomDialect is implemented by dialects supporting random ordering
+type RandomDialect interface {
+	// RandomOrder returns the ORDER BY expression shuffling the rows
+	RandomOrder() string
+}
+
+func (postgres) RandomOrder() string {
+	return "RANDOM()"
+}
+
+func (sqlite) RandomOrder() string {
+	return "RANDOM()"
+}
+
+func (mysql) RandomOrder() string {
+	return "RAND()"
+}
+
+func (sqlServer) RandomOrder() string {
+	return "NEWID()"
+}
+
+// randomOrder renders the random ordering through the dialect
+// without a dialect the postgres form is used
+func (b *QueryBuilder) randomOrder() (string, error) {
+	if b.Dialect == nil {
+		return postgres{}.RandomOrder(), nil
+	}
+	dialect, ok := b.Dialect.(RandomDialect)
+	if !ok {
+		return "", fmt.Errorf("sortOn: the dialect doesn't support %s", RandomSort)
+	}
+	return dialect.RandomOrder(), nil
+}
@@ -0,0 +1,55 @@
+package buildsql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxRangeExpansion caps the in list ranges when
+// MaxRangeExpansion isn't set
+const DefaultMaxRangeExpansion = 1000
+
+// expandRanges expands the low..high ranges of an in list on an
+// integer column into every integer between the bounds, inclusive
+// values of other columns are returned untouched
+func (b *QueryBuilder) expandRanges(values []string, columnType reflect.Type) ([]string, error) {
+	if columnType == nil || !isIntType(columnType) {
+		return values, nil
+	}
+
+	max := b.MaxRangeExpansion
+	if max <= 0 {
+		max = DefaultMaxRangeExpansion
+	}
+
+	expanded := []string{}
+	for _, value := range values {
+		low, high, ok := strings.Cut(value, "..")
+		if !ok {
+			expanded = append(expanded, value)
+			continue
+		}
+
+		from, err := strconv.ParseInt(strings.TrimSpace(low), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("range %q: %q is not a valid integer", value, low)
+		}
+		to, err := strconv.ParseInt(strings.TrimSpace(high), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("range %q: %q is not a valid integer", value, high)
+		}
+		if from > to {
+			return nil, fmt.Errorf("range %q: the low bound is greater than the high bound", value)
+		}
+		if to-from < 0 || to-from >= int64(max-len(expanded)) {
+			return nil, fmt.Errorf("range %q: expands to more than %d values", value, max)
+		}
+
+		for n := from; n <= to; n++ {
+			expanded = append(expanded, strconv.FormatInt(n, 10))
+		}
+	}
+	return expanded, nil
+}
@@ -0,0 +1,54 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderInRanges(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should expand a range on an integer column", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=p-id-in-1..3,7", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id IN (:filter_p_id_0_0, :filter_p_id_0_1, :filter_p_id_0_2, :filter_p_id_0_3)", where)
+		assert.Equal(t, "3", namedParamMap["filter_p_id_0_2"])
+		assert.Equal(t, "7", namedParamMap["filter_p_id_0_3"])
+	})
+
+	t.Run("should reject an over large range", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.MaxRangeExpansion = 5
+
+		_, _, _, err := builder.Build("filter=p-id-in-1..6", allowed)
+		assert.EqualError(t, err, `filter[0] p.id: range "1..6": expands to more than 5 values`)
+
+		_, _, _, err = builder.Build("filter=p-id-notin-1..5", allowed)
+		assert.Nil(t, err)
+
+		_, _, _, err = builder.Build("filter=p-id-in--9223372036854775808..9223372036854775807", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject non integer bounds", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.Build("filter=p-id-in-1..x", allowed)
+		assert.NotNil(t, err)
+
+		_, _, _, err = builder.Build("filter=p-id-in-5..1", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should leave ranges on other columns untouched", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, namedParamMap, err := builder.Build("filter=p-name-in-a..c", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "a..c", namedParamMap["filter_p_name_0_0"])
+	})
+}
@@ -0,0 +1,65 @@
+package buildsql
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// registry holds the models registered at startup keyed by table alias
+// with their declared filter surface keyed by type
+var registry = struct {
+	sync.RWMutex
+	models     map[string]interface{}
+	filterable map[reflect.Type]map[string]Operator
+}{models: make(map[string]interface{}), filterable: make(map[reflect.Type]map[string]Operator)}
+
+// RegisterModel registers the struct for a table alias in the package
+// registry, registering an alias again replaces its model
+// example:
+//
+//	func init() {
+//		buildsql.RegisterModel("p", Product{})
+//	}
+func RegisterModel(alias string, model interface{}) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.models[alias] = model
+	registry.filterable[reflect.TypeOf(model)], _ = declaredFields(model)
+}
+
+// NewQueryBuilderFromRegistry returns a builder whose Tables are the
+// registered models of the aliases, all of them when none are given,
+// so Build can be called with a nil allowed map
+// example:
+//
+//	builder, err := buildsql.NewQueryBuilderFromRegistry("p", "pr")
+//	where, orderBy, namedParamMap, err := builder.Build(on, nil)
+func NewQueryBuilderFromRegistry(aliases ...string) (*QueryBuilder, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	tables := make(map[string]interface{})
+	if len(aliases) == 0 {
+		for alias, model := range registry.models {
+			tables[alias] = model
+		}
+	}
+	for _, alias := range aliases {
+		model, ok := registry.models[alias]
+		if !ok {
+			return nil, fmt.Errorf("registry: %s is not a registered alias", alias)
+		}
+		tables[alias] = model
+	}
+
+	// the surfaces detected by RegisterModel are reused, not detected again
+	b := NewQueryBuilder()
+	b.Tables = tables
+	b.filterable = make(map[reflect.Type]map[string]Operator, len(tables))
+	for _, model := range tables {
+		rt := reflect.TypeOf(model)
+		b.filterable[rt] = registry.filterable[rt]
+	}
+	return b, nil
+}
@@ -0,0 +1,38 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	buildsql.RegisterModel("rp", Product{})
+	buildsql.RegisterModel("rpr", Pricing{})
+
+	t.Run("should build against the registered models", func(t *testing.T) {
+		builder, err := buildsql.NewQueryBuilderFromRegistry("rp", "rpr")
+		assert.Nil(t, err)
+
+		where, orderBy, namedParamMap, err := builder.Build("filter=rp-name-eq-gloves&filter=rpr-amount-gt-10&sortOn=-rpr-amount", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND rp.name = :filter_rp_name_0 AND rpr.amount > :filter_rpr_amount_0", where)
+		assert.Equal(t, "ORDER BY rpr.amount DESC", orderBy)
+		assert.Equal(t, 2, len(namedParamMap))
+	})
+
+	t.Run("should only allow the requested aliases", func(t *testing.T) {
+		builder, err := buildsql.NewQueryBuilderFromRegistry("rp")
+		assert.Nil(t, err)
+
+		where, _, _, err := builder.Build("filter=rp-id-eq-1&filter=rpr-amount-gt-10", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND rp.id = :filter_rp_id_0", where)
+	})
+
+	t.Run("should reject an unregistered alias", func(t *testing.T) {
+		_, err := buildsql.NewQueryBuilderFromRegistry("rp", "nope")
+		assert.NotNil(t, err)
+	})
+}
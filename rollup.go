@@ -0,0 +1,47 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RollupDialect is implemented by dialects supporting grouping subtotals
+type RollupDialect interface {
+	// GroupByRollup returns the GROUP BY clause with subtotal rows
+	GroupByRollup(columns []string) string
+}
+
+func (postgres) GroupByRollup(columns []string) string {
+	return "GROUP BY ROLLUP(" + strings.Join(columns, ", ") + ")"
+}
+
+func (mysql) GroupByRollup(columns []string) string {
+	return "GROUP BY " + strings.Join(columns, ", ") + " WITH ROLLUP"
+}
+
+func (sqlServer) GroupByRollup(columns []string) string {
+	return "GROUP BY ROLLUP(" + strings.Join(columns, ", ") + ")"
+}
+
+// groupByClause renders the GROUP BY, through the dialect when Rollup is set
+// without a dialect the standard ROLLUP(...) form is used
+func (b *QueryBuilder) groupByClause() (string, error) {
+	if !b.Rollup {
+		if len(b.GroupBy) == 0 {
+			return "", nil
+		}
+		return "GROUP BY " + strings.Join(b.GroupBy, ", "), nil
+	}
+
+	if len(b.GroupBy) == 0 {
+		return "", fmt.Errorf("rollup: requires GroupBy")
+	}
+	if b.Dialect == nil {
+		return postgres{}.GroupByRollup(b.GroupBy), nil
+	}
+	dialect, ok := b.Dialect.(RollupDialect)
+	if !ok {
+		return "", fmt.Errorf("rollup: the dialect doesn't support rollup")
+	}
+	return dialect.GroupByRollup(b.GroupBy), nil
+}
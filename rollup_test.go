@@ -0,0 +1,53 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderRollup(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	columns := []string{"p.name", "p.sku", "SUM(p.amount)"}
+
+	for _, tc := range []struct {
+		name    string
+		dialect buildsql.Dialect
+		groupBy string
+	}{
+		{"no dialect", nil, "GROUP BY ROLLUP(p.name, p.sku)"},
+		{"postgres", buildsql.Postgres, "GROUP BY ROLLUP(p.name, p.sku)"},
+		{"mysql", buildsql.MySQL, "GROUP BY p.name, p.sku WITH ROLLUP"},
+		{"sqlserver", buildsql.SQLServer, "GROUP BY ROLLUP(p.name, p.sku)"},
+	} {
+		t.Run("should render the rollup for "+tc.name, func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+			builder.Dialect = tc.dialect
+			builder.GroupBy = []string{"p.name", "p.sku"}
+			builder.Rollup = true
+
+			query, _, err := builder.BuildSelect("product p", columns, "", allowed)
+			assert.Nil(t, err)
+			assert.Equal(t, "SELECT p.name, p.sku, SUM(p.amount) FROM product p "+tc.groupBy, query)
+		})
+	}
+
+	t.Run("should require a group by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Rollup = true
+
+		_, _, err := builder.BuildSelect("product p", columns, "", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a dialect without rollup", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.SQLite
+		builder.GroupBy = []string{"p.name"}
+		builder.Rollup = true
+
+		_, _, err := builder.BuildSelect("product p", columns, "", allowed)
+		assert.NotNil(t, err)
+	})
+}
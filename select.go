@@ -0,0 +1,154 @@
+package buildsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CTE is a common table expression emitted in the WITH clause of BuildSelect
+// the query is trusted sql supplied by the caller, never by the client
+type CTE struct {
+	Name      string
+	Query     string
+	Recursive bool
+}
+
+// AddCTE registers a common table expression for BuildSelect
+// mark it Recursive for hierarchical queries like category trees
+func (b *QueryBuilder) AddCTE(cte CTE) *QueryBuilder {
+	b.CTEs = append(b.CTEs, cte)
+	return b
+}
+
+// withClause renders the registered CTEs
+// RECURSIVE belongs to the WITH clause, so it's emitted once
+// when any of the CTEs is recursive
+func (b *QueryBuilder) withClause() (string, error) {
+	if len(b.CTEs) == 0 {
+		return "", nil
+	}
+
+	recursive := false
+	ctes := []string{}
+	for _, cte := range b.CTEs {
+		if cte.Name == "" || cte.Query == "" {
+			return "", fmt.Errorf("cte: name and query are required")
+		}
+		recursive = recursive || cte.Recursive
+		ctes = append(ctes, fmt.Sprintf("%s AS (%s)", cte.Name, cte.Query))
+	}
+
+	with := "WITH "
+	if recursive {
+		with = "WITH RECURSIVE "
+	}
+	return with + strings.Join(ctes, ", "), nil
+}
+
+// BuildSelect builds a full select statement
+// from is the trusted FROM clause including any joins, columns the
+// trusted select list; filters and sorts come from the param string
+// the optional paginator's clause follows the order by, then Locking
+// example:
+//
+//	query, namedParamMap, err := builder.BuildSelect("product p", []string{"p.id", "p.name"}, on, allowed)
+//	// SELECT p.id, p.name FROM product p WHERE p.name LIKE :filter_p_name_0 ORDER BY p.id ASC
+func (b *QueryBuilder) BuildSelect(from string, columns []string, paramString string, allowed map[string]interface{}, paginator ...*Paginator) (query string, namedParamMap map[string]interface{}, err error) {
+	if from == "" {
+		return "", nil, fmt.Errorf("select: from is required")
+	}
+	req, err := b.parse(paramString)
+	if err != nil {
+		return "", nil, err
+	}
+	allowed = req.allowedTables(allowed)
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	} else {
+		req.projection = len(columns)
+	}
+
+	distinct, err := req.distinctOn(allowed)
+	if err != nil {
+		return "", nil, err
+	}
+	if distinct != "" {
+		distinct += " "
+	}
+
+	where, orderBy, namedParamMap, err := req.build(allowed)
+	if err != nil {
+		return "", nil, err
+	}
+	orderBy, err = req.paginate(orderBy, namedParamMap, paginator)
+	if err != nil {
+		return "", nil, err
+	}
+
+	locking, err := req.lockingClause()
+	if err != nil {
+		return "", nil, err
+	}
+
+	with, err := req.withClause()
+	if err != nil {
+		return "", nil, err
+	}
+
+	groupBy, err := req.groupByClause()
+	if err != nil {
+		return "", nil, err
+	}
+
+	having, havingParamMap, err := req.BuildHaving(allowed)
+	if err != nil {
+		return "", nil, err
+	}
+	for name, value := range havingParamMap {
+		namedParamMap[name] = value
+	}
+
+	parts := []string{}
+	if comment := b.comment(); comment != "" {
+		parts = append(parts, comment)
+	}
+	if with != "" {
+		parts = append(parts, with)
+	}
+	parts = append(parts, fmt.Sprintf("SELECT %s%s FROM %s", distinct, strings.Join(columns, ", "), from))
+	if where != "" {
+		parts = append(parts, "WHERE "+strings.TrimPrefix(where, " AND "))
+	}
+	if groupBy != "" {
+		parts = append(parts, groupBy)
+	}
+	if having != "" {
+		parts = append(parts, having)
+	}
+	if orderBy != "" {
+		parts = append(parts, orderBy)
+	}
+	if locking != "" {
+		parts = append(parts, locking)
+	}
+
+	return strings.Join(parts, " "), namedParamMap, nil
+}
+
+// comment renders Comment as a sql comment prefix
+// comment delimiters are stripped until none are left, so the
+// comment can't be closed early to inject sql, and the padding space
+// keeps a leading ! or + from becoming a mysql versioned comment or
+// an optimizer hint
+func (b *QueryBuilder) comment() string {
+	comment := b.Comment
+	for strings.Contains(comment, "*/") || strings.Contains(comment, "/*") {
+		comment = strings.ReplaceAll(comment, "*/", "")
+		comment = strings.ReplaceAll(comment, "/*", "")
+	}
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return ""
+	}
+	return "/* " + comment + " */"
+}
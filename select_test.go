@@ -0,0 +1,267 @@
+package buildsql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+type Category struct {
+	ID       int64  `json:"id" db:"id"`
+	ParentID int64  `json:"parent_id" db:"parent_id"`
+	Name     string `json:"name" db:"name"`
+	Depth    int64  `json:"depth" db:"depth"`
+}
+
+func TestQueryBuilderSelect(t *testing.T) {
+	t.Run("should build a select with filters and sorts", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		query, namedParamMap, err := builder.BuildSelect("product p", []string{"p.id", "p.name"}, "filter=p-name-like-cotton&sortOn=p-id", map[string]interface{}{
+			"p": Product{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT p.id, p.name FROM product p WHERE p.name LIKE :filter_p_name_0 ORDER BY p.id ASC", query)
+		assert.Equal(t, "%cotton%", namedParamMap["filter_p_name_0"])
+	})
+
+	t.Run("should build a recursive cte with a filter", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddCTE(buildsql.CTE{
+			Name:      "tree",
+			Query:     "SELECT id, parent_id, name, 0 AS depth FROM category WHERE parent_id IS NULL UNION ALL SELECT c.id, c.parent_id, c.name, t.depth + 1 FROM category c JOIN tree t ON c.parent_id = t.id",
+			Recursive: true,
+		})
+
+		query, _, err := builder.BuildSelect("tree t", nil, "filter=t-depth-lte-2", map[string]interface{}{
+			"t": Category{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "WITH RECURSIVE tree AS (SELECT id, parent_id, name, 0 AS depth FROM category WHERE parent_id IS NULL UNION ALL SELECT c.id, c.parent_id, c.name, t.depth + 1 FROM category c JOIN tree t ON c.parent_id = t.id) SELECT * FROM tree t WHERE t.depth <= :filter_t_depth_0", query)
+	})
+
+	t.Run("should emit a single RECURSIVE keyword for multiple ctes", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.
+			AddCTE(buildsql.CTE{Name: "roots", Query: "SELECT id FROM category WHERE parent_id IS NULL"}).
+			AddCTE(buildsql.CTE{Name: "tree", Query: "SELECT id FROM roots UNION ALL SELECT c.id FROM category c JOIN tree t ON c.parent_id = t.id", Recursive: true}).
+			AddCTE(buildsql.CTE{Name: "leaf", Query: "SELECT id FROM tree", Recursive: true})
+
+		query, _, err := builder.BuildSelect("tree t", []string{"t.id"}, "", map[string]interface{}{
+			"t": Category{},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, strings.Count(query, "RECURSIVE"))
+		assert.True(t, strings.HasPrefix(query, "WITH RECURSIVE roots AS ("))
+	})
+
+	t.Run("should fall back to the registered tables with a nil allowed map", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder().WithAllowedFields(map[string]interface{}{"p": Product{}, "pr": Pricing{}})
+		builder.DistinctOn = []string{"p.name"}
+		builder.GroupBy = []string{"p.name"}
+		assert.Nil(t, builder.ParseParamString("having=sum-pr-amount-gt-100"))
+
+		query, namedParamMap, err := builder.BuildSelect("product p JOIN pricing pr ON pr.product_id = p.id", []string{"p.name"}, "filter=p-sku-eq-abc", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT DISTINCT ON (p.name) p.name FROM product p JOIN pricing pr ON pr.product_id = p.id WHERE p.sku = :filter_p_sku_0 GROUP BY p.name HAVING SUM(pr.amount) > :having_sum_pr_amount_0 ORDER BY p.name ASC", query)
+		assert.Equal(t, "100", namedParamMap["having_sum_pr_amount_0"])
+	})
+
+	t.Run("should error on an incomplete cte", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.AddCTE(buildsql.CTE{Name: "tree"})
+
+		_, _, err := builder.BuildSelect("tree t", nil, "", map[string]interface{}{})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderPositionalOrderBy(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	columns := []string{"p.id", "p.name", "p.amount * 2"}
+
+	t.Run("should sort by the select column position", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.PositionalOrderBy = true
+
+		query, _, err := builder.BuildSelect("product p", columns, "sortOn=-3&sortOn=p-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT p.id, p.name, p.amount * 2 FROM product p ORDER BY 3 DESC, p.id ASC", query)
+	})
+
+	t.Run("should reject a position outside the projection", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.PositionalOrderBy = true
+
+		_, _, err := builder.BuildSelect("product p", columns, "sortOn=4", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a position without a projection", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.PositionalOrderBy = true
+
+		_, _, _, err := builder.Build("sortOn=1", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject position zero", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.PositionalOrderBy = true
+
+		err := builder.ParseParamString("sortOn=0")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should not parse positions when disabled", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		err := builder.ParseParamString("sortOn=2")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderComment(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should prepend the comment", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Comment = "app:orders,endpoint:list"
+
+		query, _, err := builder.BuildSelect("product p", []string{"p.id"}, "filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "/* app:orders,endpoint:list */ SELECT p.id FROM product p WHERE p.id = :filter_p_id_0", query)
+	})
+
+	t.Run("should neutralize a comment injection", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Comment = "x*/ DROP TABLE product; /*"
+
+		query, _, err := builder.BuildSelect("product p", []string{"p.id"}, "", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "/* x DROP TABLE product; */ SELECT p.id FROM product p", query)
+	})
+
+	t.Run("should not render a versioned comment or an optimizer hint", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		builder.Comment = "!50000 DROP TABLE product"
+		query, _, err := builder.BuildSelect("product p", []string{"p.id"}, "", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "/* !50000 DROP TABLE product */ SELECT p.id FROM product p", query)
+
+		builder.Comment = "+ MAX_EXECUTION_TIME(1)"
+		query, _, err = builder.BuildSelect("product p", []string{"p.id"}, "", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "/* + MAX_EXECUTION_TIME(1) */ SELECT p.id FROM product p", query)
+	})
+
+	t.Run("should strip nested comment delimiters", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Comment = "a**//b"
+
+		query, _, err := builder.BuildDelete("product", "filter=p-id-eq-1", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "/* ab */ DELETE FROM product WHERE id = :filter_p_id_0", query)
+	})
+}
+
+func TestQueryBuilderDistinctOn(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+	from := "product p JOIN pricing pr ON pr.product_id = p.id"
+
+	t.Run("should emit DISTINCT ON leading the order by", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+		builder.DistinctOn = []string{"p.id"}
+
+		query, _, err := builder.BuildSelect(from, []string{"p.id", "pr.amount"}, "sortOn=-pr-amount&sortOn=-p-id", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, `SELECT DISTINCT ON ("p"."id") p.id, pr.amount FROM product p JOIN pricing pr ON pr.product_id = p.id ORDER BY "p"."id" DESC, "pr"."amount" DESC`, query)
+	})
+
+	t.Run("should sort the distinct columns ascending when the client didn't", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DistinctOn = []string{"p.id"}
+
+		query, _, err := builder.BuildSelect(from, []string{"p.id", "pr.amount"}, "sortOn=-pr-amount", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "SELECT DISTINCT ON (p.id) p.id, pr.amount FROM product p JOIN pricing pr ON pr.product_id = p.id ORDER BY p.id ASC, pr.amount DESC", query)
+	})
+
+	t.Run("should reject a field that isn't allowed", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DistinctOn = []string{"p.password"}
+
+		_, _, err := builder.BuildSelect(from, nil, "", allowed)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reject a field that isn't sortable", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.DistinctOn = []string{"c.email"}
+
+		_, _, err := builder.BuildSelect("customer c", nil, "", map[string]interface{}{"c": Customer{}})
+		assert.EqualError(t, err, "distinct on: c.email is not a sortable field")
+	})
+
+	t.Run("should reject DISTINCT ON under mysql", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.MySQL
+		builder.DistinctOn = []string{"p.id"}
+
+		_, _, err := builder.BuildSelect(from, nil, "", allowed)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQueryBuilderLocking(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+	on := "filter=p-sku-eq-g1&sortOn=p-id"
+
+	for _, tc := range []struct {
+		name    string
+		dialect buildsql.Dialect
+		locking buildsql.Locking
+		query   string
+	}{
+		{"postgres", buildsql.Postgres, buildsql.Locking{Strength: buildsql.ForUpdate, SkipLocked: true},
+			`SELECT * FROM product p WHERE "p"."sku" = :filter_p_sku_0 ORDER BY "p"."id" ASC LIMIT 10 FOR UPDATE SKIP LOCKED`},
+		{"postgres share", buildsql.Postgres, buildsql.Locking{Strength: buildsql.ForShare, NoWait: true},
+			`SELECT * FROM product p WHERE "p"."sku" = :filter_p_sku_0 ORDER BY "p"."id" ASC LIMIT 10 FOR SHARE NOWAIT`},
+		{"mysql", buildsql.MySQL, buildsql.Locking{Strength: buildsql.ForUpdate},
+			"SELECT * FROM product p WHERE `p`.`sku` = :filter_p_sku_0 ORDER BY `p`.`id` ASC LIMIT 10 FOR UPDATE"},
+		{"no dialect", nil, buildsql.Locking{Strength: buildsql.ForUpdate},
+			"SELECT * FROM product p WHERE p.sku = :filter_p_sku_0 ORDER BY p.id ASC LIMIT 10 FOR UPDATE"},
+	} {
+		t.Run("should place the locking clause last for "+tc.name, func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+			builder.Dialect = tc.dialect
+			builder.Locking = tc.locking
+
+			query, _, err := builder.BuildSelect("product p", nil, on, allowed, &buildsql.Paginator{Limit: 10})
+			assert.Nil(t, err)
+			assert.Equal(t, tc.query, query)
+		})
+	}
+
+	for _, dialect := range []buildsql.Dialect{buildsql.SQLite, buildsql.SQLServer} {
+		t.Run("should reject a dialect without row locking", func(t *testing.T) {
+			builder := buildsql.NewQueryBuilder()
+			builder.Dialect = dialect
+			builder.Locking = buildsql.Locking{Strength: buildsql.ForUpdate}
+
+			_, _, err := builder.BuildSelect("product p", nil, on, allowed)
+			assert.NotNil(t, err)
+		})
+	}
+
+	t.Run("should reject SkipLocked with NoWait", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Locking = buildsql.Locking{Strength: buildsql.ForUpdate, SkipLocked: true, NoWait: true}
+
+		_, _, err := builder.BuildSelect("product p", nil, on, allowed)
+		assert.NotNil(t, err)
+	})
+}
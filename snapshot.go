@@ -0,0 +1,262 @@
+package buildsql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// snapshotVersion is bumped when the snapshot layout changes
+// incompatibly, Restore rejects the versions it doesn't know
+const snapshotVersion = 1
+
+// snapshot is the JSON layout of the parsed builder state
+type snapshot struct {
+	Version    int              `json:"version"`
+	Filters    []snapshotFilter `json:"filters,omitempty"`
+	Sorts      []snapshotSort   `json:"sorts,omitempty"`
+	Havings    []snapshotHaving `json:"havings,omitempty"`
+	Flags      []string         `json:"flags,omitempty"`
+	Combinator Combinator       `json:"combinator,omitempty"`
+	Search     string           `json:"q,omitempty"`
+	Limit      *int             `json:"limit,omitempty"`
+	Offset     *int             `json:"offset,omitempty"`
+}
+
+// snapshotFilter is a FilterField with its unexported parse state
+type snapshotFilter struct {
+	Alias         string      `json:"alias"`
+	Field         string      `json:"field"`
+	Op            Operator    `json:"op"`
+	Value         interface{} `json:"value,omitempty"`
+	Values        []string    `json:"values,omitempty"`
+	TypeHint      string      `json:"type,omitempty"`
+	BoolShorthand bool        `json:"bool,omitempty"`
+	Shorthand     bool        `json:"shorthand,omitempty"`
+	Preset        string      `json:"preset,omitempty"`
+	Group         string      `json:"group,omitempty"`
+}
+
+type snapshotSort struct {
+	Alias    string        `json:"alias,omitempty"`
+	Field    string        `json:"field,omitempty"`
+	Dir      SortDirection `json:"dir"`
+	Position int           `json:"position,omitempty"`
+}
+
+type snapshotHaving struct {
+	Aggregate Aggregate `json:"aggregate"`
+	snapshotFilter
+}
+
+// Snapshot encodes the parsed filters, sorts, havings, flags, combinator,
+// search, limit and offset as JSON, e.g. to store a user's saved view and replay it
+// later with Restore; the configuration of the builder isn't included
+// example:
+//
+//	builder.ParseParamString("filter=p-id-in-1,2&sortOn=-p-name")
+//	data, err := builder.Snapshot()
+func (b *QueryBuilder) Snapshot() ([]byte, error) {
+	snap := snapshot{
+		Version:    snapshotVersion,
+		Combinator: b.Combinator,
+		Search:     b.Search,
+		Limit:      b.Limit,
+		Offset:     b.Offset,
+	}
+	for _, field := range b.Filters {
+		snap.Filters = append(snap.Filters, newSnapshotFilter(field))
+	}
+	for _, sort := range b.Sorts {
+		snap.Sorts = append(snap.Sorts, snapshotSort{
+			Alias:    sort.TableAlias,
+			Field:    sort.FieldName,
+			Dir:      sort.Direction,
+			Position: sort.Position,
+		})
+	}
+	for _, having := range b.Havings {
+		snap.Havings = append(snap.Havings, snapshotHaving{
+			Aggregate:      having.Aggregate,
+			snapshotFilter: newSnapshotFilter(having.FilterField),
+		})
+	}
+	for _, toggle := range b.flags {
+		name := toggle.name
+		if toggle.negate {
+			name = "-" + name
+		}
+		snap.Flags = append(snap.Flags, name)
+	}
+	return json.Marshal(snap)
+}
+
+// Restore replaces the parsed state of the builder with a Snapshot
+// operators, flags and presets are checked against the current
+// configuration, preset filters are rebuilt from Presets by name and
+// fields are validated by Build as usual
+// example:
+//
+//	builder := buildsql.NewQueryBuilder()
+//	if err := builder.Restore(savedView); err != nil {
+//		return err
+//	}
+//	where, orderBy, namedParamMap, err := builder.Build("", allowed)
+func (b *QueryBuilder) Restore(data []byte) error {
+	var snap snapshot
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&snap); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("restore: snapshot version %d is not supported", snap.Version)
+	}
+
+	searchTables := make(map[string]int)
+	filters := []FilterField{}
+	presets := []string{}
+	expanded := make(map[string]bool)
+	for i, f := range snap.Filters {
+		// preset filters are rebuilt from the registered preset by name,
+		// the snapshot's copy could bypass the context and mandatory values
+		if f.Preset != "" {
+			if _, ok := b.Presets[f.Preset]; !ok {
+				return fmt.Errorf("restore: filter[%d]: preset: %s is not a registered preset", i, f.Preset)
+			}
+			if !expanded[f.Preset] {
+				expanded[f.Preset] = true
+				presets = append(presets, f.Preset)
+			}
+			continue
+		}
+		field, err := b.restoreFilter(f)
+		if err != nil {
+			return fmt.Errorf("restore: filter[%d] %s.%s: %w", i, f.Alias, f.Field, err)
+		}
+		filters = append(filters, field)
+		searchTables[field.TableAlias] = 1
+	}
+	for _, name := range presets {
+		for _, field := range b.Presets[name] {
+			field.preset = name
+			filters = append(filters, field)
+			searchTables[field.TableAlias] = 1
+		}
+	}
+
+	sorts := []SortField{}
+	for _, s := range snap.Sorts {
+		if s.Dir != ASC && s.Dir != DESC {
+			return fmt.Errorf("restore: %s is not a valid sort direction", s.Dir)
+		}
+		sorts = append(sorts, SortField{
+			TableAlias: s.Alias,
+			FieldName:  s.Field,
+			Direction:  s.Dir,
+			Position:   s.Position,
+		})
+		if s.Alias != "" {
+			searchTables[s.Alias] = 1
+		}
+	}
+
+	havings := []HavingField{}
+	for i, h := range snap.Havings {
+		if !h.Aggregate.IsValid() {
+			return fmt.Errorf("restore: having[%d]: %s is not a valid aggregate", i, h.Aggregate)
+		}
+		field, err := b.restoreFilter(h.snapshotFilter)
+		if err != nil {
+			return fmt.Errorf("restore: having[%d]: %w", i, err)
+		}
+		havings = append(havings, HavingField{Aggregate: h.Aggregate, FilterField: field})
+		searchTables[field.TableAlias] = 1
+	}
+
+	flags := []flagToggle{}
+	for _, name := range snap.Flags {
+		toggle := flagToggle{name: strings.TrimPrefix(name, "-"), negate: strings.HasPrefix(name, "-")}
+		if _, ok := b.Flags[toggle.name]; !ok {
+			return fmt.Errorf("restore: flag: %s is not a registered flag", toggle.name)
+		}
+		flags = append(flags, toggle)
+	}
+
+	for name, value := range map[string]*int{"limit": snap.Limit, "offset": snap.Offset} {
+		if value != nil && *value < 0 {
+			return fmt.Errorf("restore: %s: %d is not a valid non-negative integer", name, *value)
+		}
+	}
+
+	switch snap.Combinator {
+	case "", AndCombinator, OrCombinator:
+	default:
+		return fmt.Errorf("restore: combinator: %s is not a valid combinator", snap.Combinator)
+	}
+
+	b.Filters = filters
+	b.Sorts = sorts
+	b.Havings = havings
+	b.flags = flags
+	b.Combinator = snap.Combinator
+	b.Search = snap.Search
+	b.Limit = snap.Limit
+	b.Offset = snap.Offset
+	b.SearchTables = searchTables
+	return nil
+}
+
+// newSnapshotFilter copies the filter into its snapshot form
+func newSnapshotFilter(field FilterField) snapshotFilter {
+	return snapshotFilter{
+		Alias:         field.TableAlias,
+		Field:         field.FieldName,
+		Op:            field.Operator,
+		Value:         field.Value,
+		Values:        field.Values,
+		TypeHint:      field.TypeHint,
+		BoolShorthand: field.boolShorthand,
+		Shorthand:     field.shorthand,
+		Preset:        field.preset,
+		Group:         field.group,
+	}
+}
+
+// restoreFilter rebuilds the filter from its snapshot form, numbers
+// come back as int64 or float64 like the go values that were snapshot
+func (b *QueryBuilder) restoreFilter(f snapshotFilter) (FilterField, error) {
+	if !f.Op.IsValid() {
+		return FilterField{}, fmt.Errorf("%q is not a valid operator", f.Op)
+	}
+	if err := b.checkOperator(f.Op); err != nil {
+		return FilterField{}, err
+	}
+	if f.TypeHint != "" && !isTypeHint(f.TypeHint) {
+		return FilterField{}, fmt.Errorf("%s is not a valid type hint", f.TypeHint)
+	}
+
+	value := f.Value
+	if number, ok := value.(json.Number); ok {
+		if n, err := number.Int64(); err == nil {
+			value = n
+		} else if n, err := number.Float64(); err == nil {
+			value = n
+		} else {
+			value = number.String()
+		}
+	}
+
+	return FilterField{
+		TableAlias:    f.Alias,
+		FieldName:     f.Field,
+		Operator:      f.Op,
+		Value:         value,
+		Values:        f.Values,
+		TypeHint:      f.TypeHint,
+		boolShorthand: f.BoolShorthand,
+		shorthand:     f.Shorthand,
+		group:         f.Group,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderSnapshot(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}, "pr": Pricing{}}
+	on := "filter=p-name-like-cotton&filter=pr-amount-btw-10,20&filter=p-id-in-3,1,2&filter=p-sku-isnull" +
+		"&filter=p-slug-neq-str:g-1&filter=or:(p-id-eq-9,p-sku-eq-x)&having=sum-pr-amount-gt-100&combinator=or&sortOn=-p-name&sortOn=p-id&limit=20&offset=40"
+
+	t.Run("should build the same query after restoring a snapshot", func(t *testing.T) {
+		saved := buildsql.NewQueryBuilder()
+		saved.GroupBy = []string{"p.id"}
+		assert.Nil(t, saved.ParseParamString(on))
+		data, err := saved.Snapshot()
+		assert.Nil(t, err)
+
+		restored := buildsql.NewQueryBuilder()
+		restored.GroupBy = []string{"p.id"}
+		assert.Nil(t, restored.Restore(data))
+		assert.Equal(t, saved.Filters, restored.Filters)
+		assert.Equal(t, saved.Sorts, restored.Sorts)
+		assert.Equal(t, saved.Havings, restored.Havings)
+		assert.Equal(t, buildsql.OrCombinator, restored.Combinator)
+		assert.Equal(t, "LIMIT 20 OFFSET 40", restored.LimitOffset())
+
+		where, orderBy, namedParamMap, err := buildsql.NewQueryBuilder().Build(on, allowed)
+		assert.Nil(t, err)
+		restoredWhere, restoredOrderBy, restoredParams, err := restored.Build("", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, where, restoredWhere)
+		assert.Equal(t, orderBy, restoredOrderBy)
+		assert.Equal(t, namedParamMap, restoredParams)
+
+		having, _, err := restored.BuildHaving(allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, "HAVING SUM(pr.amount) > :having_sum_pr_amount_0", having)
+	})
+
+	t.Run("should restore numbers as go numbers", func(t *testing.T) {
+		saved := buildsql.NewQueryBuilder()
+		saved.Filters = []buildsql.FilterField{
+			{TableAlias: "p", FieldName: "id", Operator: buildsql.Equal, Value: 7},
+			{TableAlias: "p", FieldName: "amount", Operator: buildsql.GreaterThan, Value: 9.5},
+		}
+		data, err := saved.Snapshot()
+		assert.Nil(t, err)
+
+		restored := buildsql.NewQueryBuilder()
+		assert.Nil(t, restored.Restore(data))
+		assert.Equal(t, int64(7), restored.Filters[0].Value)
+		assert.Equal(t, 9.5, restored.Filters[1].Value)
+	})
+
+	t.Run("should replace the parsed state", func(t *testing.T) {
+		restored := buildsql.NewQueryBuilder()
+		assert.Nil(t, restored.ParseParamString("filter=p-id-eq-1&sortOn=p-id"))
+		assert.Nil(t, restored.Restore([]byte(`{"version":1}`)))
+		assert.Empty(t, restored.Filters)
+		assert.Empty(t, restored.Sorts)
+	})
+
+	t.Run("should rebuild the preset filters from the registered presets", func(t *testing.T) {
+		restored := buildsql.NewQueryBuilder()
+		restored.Presets = map[string][]buildsql.FilterField{
+			"untitled": {{TableAlias: "d", FieldName: "title", Operator: buildsql.IsNull}},
+		}
+		restored.BindContextValue("d", "owner_id", int64(7))
+		restored.AddMandatoryFilter(buildsql.FilterField{TableAlias: "d", FieldName: "id", Operator: buildsql.GreaterThan, Value: 0})
+		restored.MandatoryConflictPolicy = buildsql.MandatoryReject
+
+		assert.Nil(t, restored.Restore([]byte(`{"version":1,"filters":[`+
+			`{"alias":"d","field":"owner_id","op":"eq","value":99,"preset":"untitled"},`+
+			`{"alias":"d","field":"id","op":"gt","value":-1,"preset":"untitled"}]}`)))
+
+		where, _, namedParamMap, err := restored.Build("", map[string]interface{}{"d": Document{}})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND d.id > :mandatory_d_id_0 AND d.title IS NULL", where)
+		assert.Equal(t, map[string]interface{}{"mandatory_d_id_0": 0}, namedParamMap)
+
+		assert.NotNil(t, restored.Restore([]byte(`{"version":1,"filters":[{"alias":"d","field":"owner_id","op":"eq","value":99,"preset":"nope"}]}`)))
+	})
+
+	t.Run("should reject snapshots the builder doesn't allow", func(t *testing.T) {
+		restored := buildsql.NewQueryBuilder()
+		restored.DisabledOperators = []buildsql.Operator{buildsql.Like}
+
+		assert.NotNil(t, restored.Restore([]byte(`{"version":1,"filters":[{"alias":"p","field":"name","op":"like","value":"%a%"}]}`)))
+		assert.NotNil(t, restored.Restore([]byte(`{"version":1,"filters":[{"alias":"p","field":"name","op":"nope","value":"a"}]}`)))
+		assert.NotNil(t, restored.Restore([]byte(`{"version":1,"flags":["overdue"]}`)))
+		assert.NotNil(t, restored.Restore([]byte(`{"version":1,"limit":-1}`)))
+		assert.NotNil(t, restored.Restore([]byte(`{"version":2}`)))
+		assert.NotNil(t, restored.Restore([]byte(`not json`)))
+	})
+}
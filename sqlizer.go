@@ -0,0 +1,34 @@
+package buildsql
+
+// Sqlizer is a built condition satisfying the Sqlizer interface of
+// github.com/Masterminds/squirrel without depending on it
+type Sqlizer struct {
+	sql  string
+	args []interface{}
+}
+
+// ToSql returns the condition with ? placeholders and its args in
+// placeholder order; squirrel rewrites the placeholders for the db
+// no conditions render as (1=1), as squirrel's empty And does
+func (s Sqlizer) ToSql() (string, []interface{}, error) {
+	return s.sql, s.args, nil
+}
+
+// ToSqlizer builds the where for composing into a squirrel builder
+// example:
+//
+//	cond, err := builder.ToSqlizer(on, allowed)
+//	query, args, err := squirrel.Select("*").From("products p").
+//		Where(cond).
+//		PlaceholderFormat(squirrel.Dollar).
+//		ToSql()
+func (b *QueryBuilder) ToSqlizer(paramString string, allowed map[string]interface{}) (Sqlizer, error) {
+	cond, args, err := b.BuildGorm(paramString, allowed)
+	if err != nil {
+		return Sqlizer{}, err
+	}
+	if cond == "" {
+		cond = "(1=1)"
+	}
+	return Sqlizer{sql: cond, args: args}, nil
+}
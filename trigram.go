@@ -0,0 +1,36 @@
+package buildsql
+
+import "fmt"
+
+// TrigramDialect is implemented by dialects with trigram similarity,
+// postgres with the pg_trgm extension
+type TrigramDialect interface {
+	// Similar returns the predicate matching a column similar to the
+	// param above the session's similarity threshold
+	Similar(column, param string) string
+	// SimilarAbove returns the predicate matching a column whose
+	// similarity to the term param is above the threshold param
+	SimilarAbove(column, term, threshold string) string
+}
+
+// Similar uses the % operator, its threshold is pg_trgm.similarity_threshold
+func (postgres) Similar(column, param string) string {
+	return fmt.Sprintf("%s %% %s", column, param)
+}
+
+func (postgres) SimilarAbove(column, term, threshold string) string {
+	return fmt.Sprintf("similarity(%s, %s) > %s", column, term, threshold)
+}
+
+// trigram returns the trigram dialect, without a dialect the postgres
+// form is used, since only postgres has pg_trgm among the built in dialects
+func (b *QueryBuilder) trigram(op Operator) (TrigramDialect, error) {
+	if b.Dialect == nil {
+		return postgres{}, nil
+	}
+	dialect, ok := b.Dialect.(TrigramDialect)
+	if !ok {
+		return nil, fmt.Errorf("operator %s requires a dialect with trigram similarity", op)
+	}
+	return dialect, nil
+}
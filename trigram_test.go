@@ -0,0 +1,62 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderTrigram(t *testing.T) {
+	allowed := map[string]interface{}{"p": Product{}}
+
+	t.Run("should render the similarity match", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		builder.Dialect = buildsql.Postgres
+
+		where, _, namedParamMap, err := builder.Build("filter=p-name-simil-glovs", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND "p"."name" % :filter_p_name_0`, where)
+		assert.Equal(t, map[string]interface{}{"filter_p_name_0": "glovs"}, namedParamMap)
+	})
+
+	t.Run("should render the similarity above a threshold", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, _, namedParamMap, err := builder.Build("filter=p-name-similth-glovs,0.4", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND similarity(p.name, :filter_p_name_0_0) > :filter_p_name_0_1", where)
+		assert.Equal(t, map[string]interface{}{"filter_p_name_0_0": "glovs", "filter_p_name_0_1": 0.4}, namedParamMap)
+
+		builder.Dialect = buildsql.Postgres
+		where, _, args, err := builder.BuildPositional("filter=p-name-similth-glovs,0.4", allowed)
+		assert.Nil(t, err)
+		assert.Equal(t, ` AND similarity("p"."name", $1) > $2`, where)
+		assert.Equal(t, []interface{}{"glovs", 0.4}, args)
+	})
+
+	t.Run("should reject an invalid threshold", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		for _, on := range []string{"filter=p-name-similth-glovs", "filter=p-name-similth-glovs,x", "filter=p-name-similth-glovs,1.5"} {
+			_, _, _, err := builder.Build(on, allowed)
+			assert.NotNil(t, err, on)
+		}
+	})
+
+	t.Run("should reject non string columns", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		_, _, _, err := builder.Build("filter=p-id-simil-1", allowed)
+		assert.EqualError(t, err, "filter[0] p.id: simil requires a string column")
+	})
+
+	t.Run("should reject dialects without trigram similarity", func(t *testing.T) {
+		for _, dialect := range []buildsql.Dialect{buildsql.MySQL, buildsql.SQLite, buildsql.SQLServer} {
+			builder := buildsql.NewQueryBuilder()
+			builder.Dialect = dialect
+
+			_, _, _, err := builder.Build("filter=p-name-simil-glovs", allowed)
+			assert.EqualError(t, err, "filter[0] p.name: operator simil requires a dialect with trigram similarity")
+		}
+	})
+}
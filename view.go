@@ -0,0 +1,41 @@
+package buildsql
+
+import "fmt"
+
+// RegisterView registers a db view for a table alias with the mapping of
+// its logical field names to the actual view columns, for views whose
+// outputs don't map 1:1 to a struct's tags; filters and sorts are
+// validated against the logical names and rendered with the columns
+// example:
+//
+//	builder.RegisterView("v", map[string]string{"displayName": "computed_display_name"})
+//	// filter=v-displayName-eq-bob => AND v.computed_display_name = :filter_v_displayName_0
+func (b *QueryBuilder) RegisterView(alias string, columns map[string]string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("register: %s has no columns", alias)
+	}
+	for field, column := range columns {
+		if err := b.checkIdent(field, column); err != nil {
+			return fmt.Errorf("register: %s: %w", alias, err)
+		}
+	}
+
+	if b.Tables == nil {
+		b.Tables = make(map[string]interface{})
+	}
+	b.Tables[alias] = columns
+	return nil
+}
+
+// viewColumn maps a logical field of a view in the allowed map of the
+// build to its column
+func (b *QueryBuilder) viewColumn(tableAlias, fieldName string) string {
+	columns, ok := b.allowedTables(b.allowed)[tableAlias].(map[string]string)
+	if !ok {
+		return fieldName
+	}
+	if column, ok := columns[fieldName]; ok {
+		return column
+	}
+	return fieldName
+}
@@ -0,0 +1,60 @@
+package buildsql_test
+
+import (
+	"testing"
+
+	"github.com/localrivet/buildsql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilderRegisterView(t *testing.T) {
+	columns := map[string]string{
+		"displayName": "computed_display_name",
+		"id":          "user_id",
+	}
+
+	t.Run("should render the mapped view columns", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterView("v", columns))
+
+		where, orderBy, namedParamMap, err := builder.Build("filter=v-displayName-eq-bob&sortOn=-v-id", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, " AND v.computed_display_name = :filter_v_displayName_0", where)
+		assert.Equal(t, "ORDER BY v.user_id DESC", orderBy)
+		assert.Equal(t, "bob", namedParamMap["filter_v_displayName_0"])
+	})
+
+	t.Run("should validate against the logical fields", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterView("v", columns))
+
+		where, _, _, err := builder.Build("filter=v-computed_display_name-eq-bob", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "", where)
+	})
+
+	t.Run("should render the columns of a view in the allowed map", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+
+		where, orderBy, _, err := builder.Build("filter=v-displayName-eq-bob&sortOn=v-id", map[string]interface{}{"v": columns})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND v.computed_display_name = :filter_v_displayName_0", where)
+		assert.Equal(t, "ORDER BY v.user_id ASC", orderBy)
+	})
+
+	t.Run("should not map the fields of a struct allowed under a view's alias", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		assert.Nil(t, builder.RegisterView("p", map[string]string{"id": "product_id"}))
+
+		where, orderBy, _, err := builder.Build("filter=p-id-eq-1&sortOn=p-id", map[string]interface{}{"p": Product{}})
+		assert.Nil(t, err)
+		assert.Equal(t, " AND p.id = :filter_p_id_0", where)
+		assert.Equal(t, "ORDER BY p.id ASC", orderBy)
+	})
+
+	t.Run("should reject an invalid column", func(t *testing.T) {
+		builder := buildsql.NewQueryBuilder()
+		err := builder.RegisterView("v", map[string]string{"displayName": "name; drop table users"})
+		assert.NotNil(t, err)
+	})
+}